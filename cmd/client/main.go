@@ -44,7 +44,7 @@ func main() {
 	var secure bool
 
 	flag.StringVar(&host, "host", "", "target hostname or ip address")
-	flag.IntVar(&port, "port", 443, "target port")
+	flag.IntVar(&port, "port", 0, "target port, default: 443 for https, 80 for http")
 	flag.StringVar(&proto, "proto", "https", "protocol: https (default) or http")
 	flag.BoolVar(&secure, "secure", false, "validate certificates, default: false")
 	flag.StringVar(&authUser, "user", "", "username")
@@ -80,26 +80,22 @@ func main() {
 		os.Exit(1)
 	}
 
-	cli := client.NewClient()
-	if err := cli.SetHost(host); err != nil {
-		log.Fatalf("argument '-host': %s", err)
+	opts := []client.Option{
+		client.WithProtocol(proto),
+		client.WithCredentials(authUser, authPass),
 	}
-	if err := cli.SetPort(port); err != nil {
-		log.Fatalf("argument '-port': %s", err)
+	if port != 0 {
+		opts = append(opts, client.WithPort(port))
 	}
-	if err := cli.SetProtocol(proto); err != nil {
-		log.Fatalf("argument '-proto': %s", err)
-	}
-	if err := cli.SetUsername(authUser); err != nil {
-		log.Fatalf("argument '-user': %s", err)
+	if secure {
+		opts = append(opts, client.WithSecure())
 	}
-	if err := cli.SetPassword(authPass); err != nil {
-		log.Fatalf("argument '-pass': %s", err)
+	if log.GetLevel() >= log.DebugLevel {
+		opts = append(opts, client.WithLogger(log.StandardLogger()))
 	}
-	if secure {
-		if err := cli.SetSecure(); err != nil {
-			log.Fatalf("argument '-secure': %s", err)
-		}
+	cli, err := client.New(host, opts...)
+	if err != nil {
+		log.Fatalf("arguments: %s", err)
 	}
 	log.Debugf("host: %s, port: %d, secure: %t,  user: %s, cli command: %s", host, port, secure, authUser, cliCommand)
 
@@ -0,0 +1,199 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//            and Paul Schou     (github.com/pschou)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pschou/go-json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+type ShowMacAddressTableResponse struct {
+	InsAPI struct {
+		Outputs struct {
+			Output ShowMacAddressTableResponseResult `json:"output" xml:"output"`
+		} `json:"outputs" xml:"outputs"`
+		Sid     string `json:"sid" xml:"sid"`
+		Type    string `json:"type" xml:"type"`
+		Version string `json:"version" xml:"version"`
+	} `json:"ins_api" xml:"ins_api"`
+}
+
+type ShowMacAddressTableResponseResult struct {
+	Body  ShowMacAddressTableResultBody `json:"body" xml:"body"`
+	Code  string                        `json:"code" xml:"code"`
+	Input string                        `json:"input" xml:"input"`
+	Msg   string                        `json:"msg" xml:"msg"`
+}
+
+type ShowMacAddressTableResultBody struct {
+	TableMacAddress []struct {
+		RowMacAddress []struct {
+			DispAge      string `json:"disp_age" xml:"disp_age"`
+			DispIsNtfy   string `json:"disp_is_ntfy" xml:"disp_is_ntfy"`
+			DispIsSecure string `json:"disp_is_secure" xml:"disp_is_secure"`
+			DispIsStatic string `json:"disp_is_static" xml:"disp_is_static"`
+			DispMacAddr  string `json:"disp_mac_addr" xml:"disp_mac_addr"`
+			DispPort     string `json:"disp_port" xml:"disp_port"`
+			DispType     string `json:"disp_type" xml:"disp_type"`
+			DispVlan     string `json:"disp_vlan" xml:"disp_vlan"`
+		} `json:"ROW_mac_address" xml:"ROW_mac_address"`
+	} `json:"TABLE_mac_address" xml:"TABLE_mac_address"`
+}
+
+// ShowMacAddressTableResultFlat is an entry of the MAC address table.
+//
+// Type is the flag of the entry with the padding removed: "*" for a primary
+// entry, "+" for a primary entry learned over the vPC peer-link, "G" for the
+// gateway MAC of the switch, or "" for a secondary entry. The port of an
+// entry learned from a VXLAN peer, e.g. "nve1(10.5.5.5)", is split into the
+// Port "nve1" and the VTEP "10.5.5.5"; the "(R)" of a routed port, e.g.
+// "Vlan500(R)", sets Routed.
+type ShowMacAddressTableResultFlat struct {
+	MAC      string   `json:"mac" xml:"mac"`
+	Vlan     string   `json:"vlan" xml:"vlan"`
+	Type     string   `json:"type" xml:"type"`
+	Primary  bool     `json:"primary" xml:"primary"`
+	PeerLink bool     `json:"peer-link" xml:"peer-link"`
+	Gateway  bool     `json:"gateway" xml:"gateway"`
+	Age      Duration `json:"age" xml:"age"`
+	Static   bool     `json:"static" xml:"static"`
+	Secure   bool     `json:"secure" xml:"secure"`
+	Notify   bool     `json:"notify" xml:"notify"`
+	Port     string   `json:"port" xml:"port"`
+	VTEP     string   `json:"vtep,omitempty" xml:"vtep,omitempty"`
+	Routed   bool     `json:"routed,omitempty" xml:"routed,omitempty"`
+}
+
+func (d *ShowMacAddressTableResponse) Flat() (out []ShowMacAddressTableResultFlat) {
+	return d.InsAPI.Outputs.Output.Flat()
+}
+func (d *ShowMacAddressTableResponseResult) Flat() (out []ShowMacAddressTableResultFlat) {
+	for _, Tm := range d.Body.TableMacAddress {
+		for _, Rm := range Tm.RowMacAddress {
+			typ := strings.TrimSpace(Rm.DispType)
+			port, vtep, routed := splitMacAddressPort(Rm.DispPort)
+			out = append(out, ShowMacAddressTableResultFlat{
+				MAC:      Rm.DispMacAddr,
+				Vlan:     strings.TrimSpace(Rm.DispVlan),
+				Type:     typ,
+				Primary:  typ == "*" || typ == "+",
+				PeerLink: typ == "+",
+				Gateway:  typ == "G",
+				Age:      parseMacAddressAge(Rm.DispAge),
+				Static:   Rm.DispIsStatic == "enabled",
+				Secure:   Rm.DispIsSecure == "enabled",
+				Notify:   Rm.DispIsNtfy == "enabled",
+				Port:     port,
+				VTEP:     vtep,
+				Routed:   routed,
+			})
+		}
+	}
+	return
+}
+
+// parseMacAddressAge returns the age of an entry, which NX-OS reports in
+// seconds, or "-" and "NA" for the entries that do not age.
+func parseMacAddressAge(s string) Duration {
+	sec, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return Duration(sec * 1e9)
+}
+
+// splitMacAddressPort splits the port of an entry into the interface and
+// its annotation, the VTEP of a VXLAN peer or "R" of a routed port.
+func splitMacAddressPort(s string) (port, vtep string, routed bool) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexByte(s, '(')
+	if i < 0 || !strings.HasSuffix(s, ")") {
+		return s, "", false
+	}
+	port, note := s[:i], s[i+1:len(s)-1]
+	switch {
+	case note == "R":
+		routed = true
+	case net.ParseIP(note) != nil:
+		vtep = note
+	default:
+		return s, "", false
+	}
+	return
+}
+
+// NewShowMacAddressTableFromString returns instance from an input string.
+func NewShowMacAddressTableFromString(s string) (*ShowMacAddressTableResponse, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowMacAddressTableFromReader(strings.NewReader(s))
+}
+
+// NewShowMacAddressTableFromBytes returns instance from an input byte array.
+func NewShowMacAddressTableFromBytes(s []byte) (*ShowMacAddressTableResponse, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowMacAddressTableFromReader(bytes.NewReader(s))
+}
+
+// NewShowMacAddressTableFromReader returns instance from an input reader.
+func NewShowMacAddressTableFromReader(s io.Reader) (*ShowMacAddressTableResponse, error) {
+	ShowMacAddressTableResponseDat := &ShowMacAddressTableResponse{}
+	jsonDec := json.NewDecoder(s)
+	jsonDec.UseAutoConvert()
+	jsonDec.UseSlice()
+	err := jsonDec.Decode(ShowMacAddressTableResponseDat)
+	if err != nil {
+		return nil, fmt.Errorf("parsing error: %s", err)
+	}
+	return ShowMacAddressTableResponseDat, nil
+}
+
+// NewShowMacAddressTableResultFromString returns instance from an input string.
+func NewShowMacAddressTableResultFromString(s string) (*ShowMacAddressTableResponseResult, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowMacAddressTableResultFromReader(strings.NewReader(s))
+}
+
+// NewShowMacAddressTableResultFromBytes returns instance from an input byte array.
+func NewShowMacAddressTableResultFromBytes(s []byte) (*ShowMacAddressTableResponseResult, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowMacAddressTableResultFromReader(bytes.NewReader(s))
+}
+
+// NewShowMacAddressTableResultFromReader returns instance from an input reader.
+func NewShowMacAddressTableResultFromReader(s io.Reader) (*ShowMacAddressTableResponseResult, error) {
+	ShowMacAddressTableResponseResultDat := &ShowMacAddressTableResponseResult{}
+	jsonDec := json.NewDecoder(s)
+	jsonDec.UseAutoConvert()
+	jsonDec.UseSlice()
+	err := jsonDec.Decode(ShowMacAddressTableResponseResultDat)
+	if err != nil {
+		return nil, fmt.Errorf("parsing error: %s", err)
+	}
+	return ShowMacAddressTableResponseResultDat, nil
+}
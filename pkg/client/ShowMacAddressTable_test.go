@@ -0,0 +1,134 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//            and Paul Schou     (github.com/pschou)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestParseShowMacAddressTableJsonOutput(t *testing.T) {
+	testFailed := 0
+	outputDir := "../../assets/requests"
+
+	for i, test := range []struct {
+		input      string
+		exp        []ShowMacAddressTableResultFlat
+		shouldFail bool
+		shouldErr  bool
+	}{
+		{
+			input: "show.mac.address.table.1",
+			exp: []ShowMacAddressTableResultFlat{
+				{
+					MAC:     "c0f7.ed1f.7640",
+					Vlan:    "500",
+					Type:    "*",
+					Primary: true,
+					Age:     Duration(203308810 * 1e9),
+					Static:  true,
+					Port:    "nve1",
+					VTEP:    "10.5.5.5",
+				},
+				{
+					MAC:     "33ae.d05a.ea6e",
+					Vlan:    "500",
+					Type:    "G",
+					Gateway: true,
+					Static:  true,
+					Port:    "Vlan500",
+					Routed:  true,
+				},
+			},
+			shouldFail: false,
+			shouldErr:  false,
+		},
+	} {
+		fp := fmt.Sprintf("%s/resp.%s.json", outputDir, test.input)
+		content, err := ioutil.ReadFile(fp)
+		if err != nil {
+			t.Logf("FAIL: Test %d: failed reading '%s', error: %v", i, fp, err)
+			testFailed++
+			continue
+		}
+		// The fixture is a JSON-RPC response.
+		var resp JSONRPCResponse
+		if err := json.Unmarshal(content, &resp); err != nil {
+			t.Logf("FAIL: Test %d: failed parsing '%s', error: %v", i, fp, err)
+			testFailed++
+			continue
+		}
+		dat, err := NewShowMacAddressTableResultFromBytes(resp.Result)
+		if err != nil {
+			if !test.shouldErr {
+				t.Logf("FAIL: Test %d: input '%s', expected to pass, but threw error: %v", i, test.input, err)
+				testFailed++
+				continue
+			}
+		} else {
+			if test.shouldErr {
+				t.Logf("FAIL: Test %d: input '%s', expected to throw error, but passed: %v", i, test.input, dat)
+				testFailed++
+				continue
+			}
+		}
+
+		if dat != nil {
+			if flat := dat.Flat(); !reflect.DeepEqual(test.exp, flat) {
+				t.Logf("FAIL: Test %d: input '%s', expected:\n%#v\ngot:\n%#v", i, test.input, test.exp, flat)
+				testFailed++
+				continue
+			}
+		}
+
+		if test.shouldFail {
+			t.Logf("FAIL: Test %d: input '%s', expected to fail, but passed", i, test.input)
+			testFailed++
+			continue
+		}
+
+		t.Logf("PASS: Test %d: input '%s', expected to pass, received: %v", i, test.input, dat.Flat())
+	}
+	if testFailed > 0 {
+		t.Fatalf("Failed %d tests", testFailed)
+	}
+}
+
+func TestSplitMacAddressPort(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		port   string
+		vtep   string
+		routed bool
+	}{
+		{input: "Eth1/1", port: "Eth1/1"},
+		{input: "Po10", port: "Po10"},
+		{input: "nve1(10.5.5.5)", port: "nve1", vtep: "10.5.5.5"},
+		{input: "nve1(2001:db8::1)", port: "nve1", vtep: "2001:db8::1"},
+		{input: "Vlan500(R)", port: "Vlan500", routed: true},
+		{input: "sup-eth1(R)", port: "sup-eth1", routed: true},
+		{input: "vPC Peer-Link(R)", port: "vPC Peer-Link", routed: true},
+		{input: "Eth1/1(X)", port: "Eth1/1(X)"},
+	} {
+		port, vtep, routed := splitMacAddressPort(test.input)
+		if port != test.port || vtep != test.vtep || routed != test.routed {
+			t.Errorf("%q: expected %q, %q, %v, got %q, %q, %v", test.input, test.port, test.vtep, test.routed, port, vtep, routed)
+		}
+	}
+}
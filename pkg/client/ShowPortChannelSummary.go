@@ -0,0 +1,313 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//            and Paul Schou     (github.com/pschou)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pschou/go-json"
+	"io"
+	"strings"
+)
+
+// PortChannelLayer is the layer of a port-channel, the "S" or "R" flag of
+// "show port-channel summary".
+type PortChannelLayer string
+
+const (
+	PortChannelSwitched PortChannelLayer = "switched"
+	PortChannelRouted   PortChannelLayer = "routed"
+)
+
+// PortChannelStatus is the state of a port-channel, the "U", "D" or "M"
+// flag of "show port-channel summary".
+type PortChannelStatus string
+
+const (
+	PortChannelUp PortChannelStatus = "up"
+	// PortChannelDown is a port-channel without bundled members.
+	PortChannelDown PortChannelStatus = "down"
+	// PortChannelNotInUse is a port-channel which does not meet its
+	// min-links.
+	PortChannelNotInUse PortChannelStatus = "not-in-use"
+)
+
+// PortChannelMemberStatus is the state of a member of a port-channel, the
+// flag in parentheses after the member in "show port-channel summary".
+type PortChannelMemberStatus string
+
+const (
+	PortChannelMemberBundled       PortChannelMemberStatus = "bundled"
+	PortChannelMemberDown          PortChannelMemberStatus = "down"
+	PortChannelMemberIndividual    PortChannelMemberStatus = "individual"
+	PortChannelMemberHotStandby    PortChannelMemberStatus = "hot-standby"
+	PortChannelMemberSuspended     PortChannelMemberStatus = "suspended"
+	PortChannelMemberModuleRemoved PortChannelMemberStatus = "module-removed"
+	PortChannelMemberBFDWait       PortChannelMemberStatus = "bfd-wait"
+	// PortChannelMemberDelayLACP is a member up in delay-lacp mode, which
+	// forwards traffic before its LACP negotiation completes.
+	PortChannelMemberDelayLACP PortChannelMemberStatus = "delay-lacp"
+)
+
+// PortChannelUnknown is the state of a flag this package does not know.
+const PortChannelUnknown = "unknown"
+
+var portChannelLayers = map[string]PortChannelLayer{
+	"S": PortChannelSwitched,
+	"R": PortChannelRouted,
+}
+
+var portChannelStatuses = map[string]PortChannelStatus{
+	"U": PortChannelUp,
+	"D": PortChannelDown,
+	"M": PortChannelNotInUse,
+}
+
+var portChannelMemberStatuses = map[string]PortChannelMemberStatus{
+	"P": PortChannelMemberBundled,
+	"D": PortChannelMemberDown,
+	"I": PortChannelMemberIndividual,
+	"H": PortChannelMemberHotStandby,
+	"s": PortChannelMemberSuspended,
+	"r": PortChannelMemberModuleRemoved,
+	"b": PortChannelMemberBFDWait,
+	"p": PortChannelMemberDelayLACP,
+}
+
+// ParsePortChannelLayer returns the layer of the flag, e.g. "S".
+func ParsePortChannelLayer(flag string) PortChannelLayer {
+	if l, found := portChannelLayers[strings.TrimSpace(flag)]; found {
+		return l
+	}
+	return PortChannelUnknown
+}
+
+// ParsePortChannelStatus returns the state of the port-channel flag, e.g.
+// "U".
+func ParsePortChannelStatus(flag string) PortChannelStatus {
+	if s, found := portChannelStatuses[strings.TrimSpace(flag)]; found {
+		return s
+	}
+	return PortChannelUnknown
+}
+
+// ParsePortChannelMemberStatus returns the state of the member flag, e.g.
+// "P". The flags are case sensitive, "s" is suspended while "S" is not a
+// member flag.
+func ParsePortChannelMemberStatus(flag string) PortChannelMemberStatus {
+	if s, found := portChannelMemberStatuses[strings.TrimSpace(flag)]; found {
+		return s
+	}
+	return PortChannelUnknown
+}
+
+type ShowPortChannelSummaryResponse struct {
+	InsAPI struct {
+		Outputs struct {
+			Output ShowPortChannelSummaryResponseResult `json:"output" xml:"output"`
+		} `json:"outputs" xml:"outputs"`
+		Sid     string `json:"sid" xml:"sid"`
+		Type    string `json:"type" xml:"type"`
+		Version string `json:"version" xml:"version"`
+	} `json:"ins_api" xml:"ins_api"`
+}
+
+type ShowPortChannelSummaryResponseResult struct {
+	Body  ShowPortChannelSummaryResultBody `json:"body" xml:"body"`
+	Code  string                           `json:"code" xml:"code"`
+	Input string                           `json:"input" xml:"input"`
+	Msg   string                           `json:"msg" xml:"msg"`
+}
+
+type ShowPortChannelSummaryResultBody struct {
+	TableChannel []struct {
+		RowChannel []struct {
+			Group       int    `json:"group" xml:"group"`
+			PortChannel string `json:"port-channel" xml:"port-channel"`
+			Layer       string `json:"layer" xml:"layer"`
+			Status      string `json:"status" xml:"status"`
+			Type        string `json:"type" xml:"type"`
+			Prtcl       string `json:"prtcl" xml:"prtcl"`
+			TableMember []struct {
+				RowMember []struct {
+					Port       string `json:"port" xml:"port"`
+					PortStatus string `json:"port-status" xml:"port-status"`
+				} `json:"ROW_member" xml:"ROW_member"`
+			} `json:"TABLE_member" xml:"TABLE_member"`
+		} `json:"ROW_channel" xml:"ROW_channel"`
+	} `json:"TABLE_channel" xml:"TABLE_channel"`
+}
+
+// ShowPortChannelSummaryResultFlat is a member of a port-channel. A
+// port-channel without members has a single row with an empty Port.
+type ShowPortChannelSummaryResultFlat struct {
+	Group       int                     `json:"group" xml:"group"`
+	PortChannel string                  `json:"port-channel" xml:"port-channel"`
+	Layer       PortChannelLayer        `json:"layer" xml:"layer"`
+	Status      PortChannelStatus       `json:"status" xml:"status"`
+	Type        string                  `json:"type" xml:"type"`
+	Protocol    string                  `json:"protocol" xml:"protocol"`
+	Port        string                  `json:"port,omitempty" xml:"port,omitempty"`
+	PortStatus  PortChannelMemberStatus `json:"port-status,omitempty" xml:"port-status,omitempty"`
+}
+
+func (d *ShowPortChannelSummaryResponse) Flat() (out []ShowPortChannelSummaryResultFlat) {
+	return d.InsAPI.Outputs.Output.Flat()
+}
+func (d *ShowPortChannelSummaryResponseResult) Flat() (out []ShowPortChannelSummaryResultFlat) {
+	for _, Tc := range d.Body.TableChannel {
+		for _, Rc := range Tc.RowChannel {
+			row := ShowPortChannelSummaryResultFlat{
+				Group:       Rc.Group,
+				PortChannel: Rc.PortChannel,
+				Layer:       ParsePortChannelLayer(Rc.Layer),
+				Status:      ParsePortChannelStatus(Rc.Status),
+				Type:        Rc.Type,
+				Protocol:    Rc.Prtcl,
+			}
+			members := 0
+			for _, Tm := range Rc.TableMember {
+				for _, Rm := range Tm.RowMember {
+					row.Port = Rm.Port
+					row.PortStatus = ParsePortChannelMemberStatus(Rm.PortStatus)
+					out = append(out, row)
+					members++
+				}
+			}
+			if members == 0 {
+				out = append(out, row)
+			}
+		}
+	}
+	return
+}
+
+// PortChannelBundle is the number of members of a port-channel.
+type PortChannelBundle struct {
+	Group       int               `json:"group" xml:"group"`
+	PortChannel string            `json:"port-channel" xml:"port-channel"`
+	Status      PortChannelStatus `json:"status" xml:"status"`
+	// Configured is the number of members of the port-channel, whatever
+	// their state.
+	Configured int `json:"configured" xml:"configured"`
+	// Bundled is the number of members in PortChannelMemberBundled state.
+	Bundled int `json:"bundled" xml:"bundled"`
+}
+
+// Degraded reports whether fewer members are bundled than configured.
+func (b PortChannelBundle) Degraded() bool {
+	return b.Bundled < b.Configured
+}
+
+func (d *ShowPortChannelSummaryResponse) Bundles() []PortChannelBundle {
+	return d.InsAPI.Outputs.Output.Bundles()
+}
+
+// Bundles returns the member counts of the port-channels.
+func (d *ShowPortChannelSummaryResponseResult) Bundles() (out []PortChannelBundle) {
+	for _, Tc := range d.Body.TableChannel {
+		for _, Rc := range Tc.RowChannel {
+			b := PortChannelBundle{
+				Group:       Rc.Group,
+				PortChannel: Rc.PortChannel,
+				Status:      ParsePortChannelStatus(Rc.Status),
+			}
+			for _, Tm := range Rc.TableMember {
+				for _, Rm := range Tm.RowMember {
+					b.Configured++
+					if ParsePortChannelMemberStatus(Rm.PortStatus) == PortChannelMemberBundled {
+						b.Bundled++
+					}
+				}
+			}
+			out = append(out, b)
+		}
+	}
+	return
+}
+
+func (d *ShowPortChannelSummaryResponse) Degraded() []PortChannelBundle {
+	return d.InsAPI.Outputs.Output.Degraded()
+}
+
+// Degraded returns the port-channels with fewer members bundled than
+// configured.
+func (d *ShowPortChannelSummaryResponseResult) Degraded() (out []PortChannelBundle) {
+	for _, b := range d.Bundles() {
+		if b.Degraded() {
+			out = append(out, b)
+		}
+	}
+	return
+}
+
+// NewShowPortChannelSummaryFromString returns instance from an input string.
+func NewShowPortChannelSummaryFromString(s string) (*ShowPortChannelSummaryResponse, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowPortChannelSummaryFromReader(strings.NewReader(s))
+}
+
+// NewShowPortChannelSummaryFromBytes returns instance from an input byte array.
+func NewShowPortChannelSummaryFromBytes(s []byte) (*ShowPortChannelSummaryResponse, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowPortChannelSummaryFromReader(bytes.NewReader(s))
+}
+
+// NewShowPortChannelSummaryFromReader returns instance from an input reader.
+func NewShowPortChannelSummaryFromReader(s io.Reader) (*ShowPortChannelSummaryResponse, error) {
+	ShowPortChannelSummaryResponseDat := &ShowPortChannelSummaryResponse{}
+	jsonDec := json.NewDecoder(s)
+	jsonDec.UseAutoConvert()
+	jsonDec.UseSlice()
+	err := jsonDec.Decode(ShowPortChannelSummaryResponseDat)
+	if err != nil {
+		return nil, fmt.Errorf("parsing error: %s", err)
+	}
+	return ShowPortChannelSummaryResponseDat, nil
+}
+
+// NewShowPortChannelSummaryResultFromString returns instance from an input string.
+func NewShowPortChannelSummaryResultFromString(s string) (*ShowPortChannelSummaryResponseResult, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowPortChannelSummaryResultFromReader(strings.NewReader(s))
+}
+
+// NewShowPortChannelSummaryResultFromBytes returns instance from an input byte array.
+func NewShowPortChannelSummaryResultFromBytes(s []byte) (*ShowPortChannelSummaryResponseResult, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowPortChannelSummaryResultFromReader(bytes.NewReader(s))
+}
+
+// NewShowPortChannelSummaryResultFromReader returns instance from an input reader.
+func NewShowPortChannelSummaryResultFromReader(s io.Reader) (*ShowPortChannelSummaryResponseResult, error) {
+	ShowPortChannelSummaryResponseResultDat := &ShowPortChannelSummaryResponseResult{}
+	jsonDec := json.NewDecoder(s)
+	jsonDec.UseAutoConvert()
+	jsonDec.UseSlice()
+	err := jsonDec.Decode(ShowPortChannelSummaryResponseResultDat)
+	if err != nil {
+		return nil, fmt.Errorf("parsing error: %s", err)
+	}
+	return ShowPortChannelSummaryResponseResultDat, nil
+}
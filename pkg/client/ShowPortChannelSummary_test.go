@@ -0,0 +1,119 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//            and Paul Schou     (github.com/pschou)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestParseShowPortChannelSummaryJsonOutput(t *testing.T) {
+	outputDir := "../../assets/requests"
+	fp := fmt.Sprintf("%s/resp.show.port.channel.summary.1.json", outputDir)
+	content, err := ioutil.ReadFile(fp)
+	if err != nil {
+		t.Fatalf("failed reading '%s', error: %v", fp, err)
+	}
+	// The fixture is a JSON-RPC response.
+	var resp JSONRPCResponse
+	if err := json.Unmarshal(content, &resp); err != nil {
+		t.Fatalf("failed parsing '%s', error: %v", fp, err)
+	}
+	dat, err := NewShowPortChannelSummaryResultFromBytes(resp.Result)
+	if err != nil {
+		t.Fatalf("failed parsing '%s', error: %v", fp, err)
+	}
+
+	flat := dat.Flat()
+	if len(flat) != 14 {
+		t.Fatalf("expected 14 members, got %d", len(flat))
+	}
+	exp := ShowPortChannelSummaryResultFlat{
+		Group:       2,
+		PortChannel: "port-channel2",
+		Layer:       PortChannelSwitched,
+		Status:      PortChannelUp,
+		Type:        "Eth",
+		Protocol:    "LACP",
+		Port:        "Ethernet1/10",
+		PortStatus:  PortChannelMemberBundled,
+	}
+	if !reflect.DeepEqual(exp, flat[2]) {
+		t.Fatalf("expected:\n%#v\ngot:\n%#v", exp, flat[2])
+	}
+
+	expBundles := []PortChannelBundle{
+		{Group: 1, PortChannel: "port-channel1", Status: PortChannelUp, Configured: 2, Bundled: 2},
+		{Group: 2, PortChannel: "port-channel2", Status: PortChannelUp, Configured: 6, Bundled: 6},
+		{Group: 3, PortChannel: "port-channel3", Status: PortChannelUp, Configured: 6, Bundled: 6},
+	}
+	if bundles := dat.Bundles(); !reflect.DeepEqual(expBundles, bundles) {
+		t.Fatalf("expected:\n%#v\ngot:\n%#v", expBundles, bundles)
+	}
+	if degraded := dat.Degraded(); len(degraded) != 0 {
+		t.Fatalf("expected no degraded port-channels, got %#v", degraded)
+	}
+}
+
+func TestShowPortChannelSummaryDegraded(t *testing.T) {
+	dat, err := NewShowPortChannelSummaryResultFromString(`{"body": {"TABLE_channel": {"ROW_channel": [
+		{"group": "10", "port-channel": "port-channel10", "layer": "R", "status": "U", "type": "Eth", "prtcl": "LACP",
+		 "TABLE_member": {"ROW_member": [
+			{"port": "Ethernet1/1", "port-status": "P"},
+			{"port": "Ethernet1/2", "port-status": "s"},
+			{"port": "Ethernet1/3", "port-status": "D"}]}},
+		{"group": "11", "port-channel": "port-channel11", "layer": "S", "status": "M", "type": "Eth", "prtcl": "LACP",
+		 "TABLE_member": {"ROW_member": {"port": "Ethernet1/4", "port-status": "H"}}},
+		{"group": "12", "port-channel": "port-channel12", "layer": "S", "status": "D", "type": "Eth", "prtcl": "NONE"}
+	]}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat := dat.Flat()
+	var got []PortChannelMemberStatus
+	for _, row := range flat {
+		got = append(got, row.PortStatus)
+	}
+	exp := []PortChannelMemberStatus{
+		PortChannelMemberBundled,
+		PortChannelMemberSuspended,
+		PortChannelMemberDown,
+		PortChannelMemberHotStandby,
+		"",
+	}
+	if !reflect.DeepEqual(exp, got) {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	if flat[0].Layer != PortChannelRouted || flat[3].Status != PortChannelNotInUse {
+		t.Fatalf("unexpected flags: %#v", flat)
+	}
+
+	expDegraded := []PortChannelBundle{
+		{Group: 10, PortChannel: "port-channel10", Status: PortChannelUp, Configured: 3, Bundled: 1},
+		{Group: 11, PortChannel: "port-channel11", Status: PortChannelNotInUse, Configured: 1, Bundled: 0},
+	}
+	if degraded := dat.Degraded(); !reflect.DeepEqual(expDegraded, degraded) {
+		t.Fatalf("expected:\n%#v\ngot:\n%#v", expDegraded, degraded)
+	}
+
+	if s := ParsePortChannelMemberStatus("X"); s != PortChannelUnknown {
+		t.Fatalf("expected %q, got %q", PortChannelUnknown, s)
+	}
+}
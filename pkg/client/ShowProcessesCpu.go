@@ -0,0 +1,231 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//            and Paul Schou     (github.com/pschou)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pschou/go-json"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ShowProcessesCpuResponse struct {
+	InsAPI struct {
+		Outputs struct {
+			Output ShowProcessesCpuResponseResult `json:"output" xml:"output"`
+		} `json:"outputs" xml:"outputs"`
+		Sid     string `json:"sid" xml:"sid"`
+		Type    string `json:"type" xml:"type"`
+		Version string `json:"version" xml:"version"`
+	} `json:"ins_api" xml:"ins_api"`
+}
+
+type ShowProcessesCpuResponseResult struct {
+	Body  ShowProcessesCpuResultBody `json:"body" xml:"body"`
+	Code  string                     `json:"code" xml:"code"`
+	Input string                     `json:"input" xml:"input"`
+	Msg   string                     `json:"msg" xml:"msg"`
+}
+
+type ShowProcessesCpuResultBody struct {
+	TableProcessCpu []struct {
+		RowProcessCpu []struct {
+			PID     int    `json:"pid" xml:"pid"`
+			Process string `json:"process" xml:"process"`
+			// Runtime is the CPU time of the process in milliseconds.
+			Runtime uint64  `json:"runtime" xml:"runtime"`
+			Invoked uint64  `json:"invoked" xml:"invoked"`
+			Usecs   uint64  `json:"usecs" xml:"usecs"`
+			OneSec  float32 `json:"onesec" xml:"onesec"`
+		} `json:"ROW_process_cpu" xml:"ROW_process_cpu"`
+	} `json:"TABLE_process_cpu" xml:"TABLE_process_cpu"`
+	IdlePercent   float32 `json:"idle_percent" xml:"idle_percent"`
+	KernelPercent float32 `json:"kernel_percent" xml:"kernel_percent"`
+	UserPercent   float32 `json:"user_percent" xml:"user_percent"`
+}
+
+// ShowProcessesCpuResultFlat is the CPU usage of a process. Usecs is the
+// average CPU time of an invocation in microseconds, and OneSec the CPU
+// percentage of the process over the last second. NX-OS reports no
+// five-second or one-minute percentages per process; use Delta for the
+// usage over a longer period.
+type ShowProcessesCpuResultFlat struct {
+	PID     int      `json:"pid" xml:"pid"`
+	Process string   `json:"process" xml:"process"`
+	Runtime Duration `json:"runtime" xml:"runtime"`
+	Invoked uint64   `json:"invoked" xml:"invoked"`
+	Usecs   uint64   `json:"usecs" xml:"usecs"`
+	OneSec  float32  `json:"onesec" xml:"onesec"`
+}
+
+func (d *ShowProcessesCpuResponse) Flat() (out []ShowProcessesCpuResultFlat) {
+	return d.InsAPI.Outputs.Output.Flat()
+}
+func (d *ShowProcessesCpuResponseResult) Flat() (out []ShowProcessesCpuResultFlat) {
+	for _, Tp := range d.Body.TableProcessCpu {
+		for _, Rp := range Tp.RowProcessCpu {
+			out = append(out, ShowProcessesCpuResultFlat{
+				PID:     Rp.PID,
+				Process: Rp.Process,
+				Runtime: Duration(Rp.Runtime * uint64(time.Millisecond)),
+				Invoked: Rp.Invoked,
+				Usecs:   Rp.Usecs,
+				OneSec:  Rp.OneSec,
+			})
+		}
+	}
+	return
+}
+
+func (d *ShowProcessesCpuResponse) TopN(n int) []ShowProcessesCpuResultFlat {
+	return d.InsAPI.Outputs.Output.TopN(n)
+}
+
+// TopN returns the n processes with the highest CPU usage, by their
+// one-second percentage and then by their runtime. A non-positive n returns all processes.
+func (d *ShowProcessesCpuResponseResult) TopN(n int) []ShowProcessesCpuResultFlat {
+	out := d.Flat()
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.OneSec != b.OneSec {
+			return a.OneSec > b.OneSec
+		}
+		return a.Runtime > b.Runtime
+	})
+	if n > 0 && n < len(out) {
+		out = out[:n]
+	}
+	return out
+}
+
+// ShowProcessesCpuDelta is the CPU usage of a process between two samples
+// of "show processes cpu".
+type ShowProcessesCpuDelta struct {
+	PID     int      `json:"pid" xml:"pid"`
+	Process string   `json:"process" xml:"process"`
+	Runtime Duration `json:"runtime" xml:"runtime"`
+	Invoked uint64   `json:"invoked" xml:"invoked"`
+	// Percent is Runtime as a percentage of the time between the samples,
+	// it exceeds 100 for a process busy on several CPUs.
+	Percent float32 `json:"percent" xml:"percent"`
+}
+
+func (d *ShowProcessesCpuResponse) Delta(prev *ShowProcessesCpuResponse, elapsed time.Duration) []ShowProcessesCpuDelta {
+	var prevResult *ShowProcessesCpuResponseResult
+	if prev != nil {
+		prevResult = &prev.InsAPI.Outputs.Output
+	}
+	return d.InsAPI.Outputs.Output.Delta(prevResult, elapsed)
+}
+
+// Delta returns the CPU usage of the processes since the prev sample, which
+// was taken elapsed before d, sorted by the highest runtime first. The
+// processes are matched by PID and name; a process not in prev, e.g. one
+// restarted since, is counted from its start. Percent is zero when elapsed
+// is not positive.
+func (d *ShowProcessesCpuResponseResult) Delta(prev *ShowProcessesCpuResponseResult, elapsed time.Duration) (out []ShowProcessesCpuDelta) {
+	type processKey struct {
+		pid     int
+		process string
+	}
+	before := map[processKey]ShowProcessesCpuResultFlat{}
+	if prev != nil {
+		for _, p := range prev.Flat() {
+			before[processKey{p.PID, p.Process}] = p
+		}
+	}
+	for _, p := range d.Flat() {
+		delta := ShowProcessesCpuDelta{
+			PID:     p.PID,
+			Process: p.Process,
+			Runtime: p.Runtime,
+			Invoked: p.Invoked,
+		}
+		if b, found := before[processKey{p.PID, p.Process}]; found && b.Runtime <= p.Runtime && b.Invoked <= p.Invoked {
+			delta.Runtime -= b.Runtime
+			delta.Invoked -= b.Invoked
+		}
+		if elapsed > 0 {
+			delta.Percent = float32(float64(delta.Runtime) / float64(elapsed) * 100)
+		}
+		out = append(out, delta)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Runtime > out[j].Runtime
+	})
+	return
+}
+
+// NewShowProcessesCpuFromString returns instance from an input string.
+func NewShowProcessesCpuFromString(s string) (*ShowProcessesCpuResponse, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowProcessesCpuFromReader(strings.NewReader(s))
+}
+
+// NewShowProcessesCpuFromBytes returns instance from an input byte array.
+func NewShowProcessesCpuFromBytes(s []byte) (*ShowProcessesCpuResponse, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowProcessesCpuFromReader(bytes.NewReader(s))
+}
+
+// NewShowProcessesCpuFromReader returns instance from an input reader.
+func NewShowProcessesCpuFromReader(s io.Reader) (*ShowProcessesCpuResponse, error) {
+	ShowProcessesCpuResponseDat := &ShowProcessesCpuResponse{}
+	jsonDec := json.NewDecoder(s)
+	jsonDec.UseAutoConvert()
+	jsonDec.UseSlice()
+	err := jsonDec.Decode(ShowProcessesCpuResponseDat)
+	if err != nil {
+		return nil, fmt.Errorf("parsing error: %s", err)
+	}
+	return ShowProcessesCpuResponseDat, nil
+}
+
+// NewShowProcessesCpuResultFromString returns instance from an input string.
+func NewShowProcessesCpuResultFromString(s string) (*ShowProcessesCpuResponseResult, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowProcessesCpuResultFromReader(strings.NewReader(s))
+}
+
+// NewShowProcessesCpuResultFromBytes returns instance from an input byte array.
+func NewShowProcessesCpuResultFromBytes(s []byte) (*ShowProcessesCpuResponseResult, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return NewShowProcessesCpuResultFromReader(bytes.NewReader(s))
+}
+
+// NewShowProcessesCpuResultFromReader returns instance from an input reader.
+func NewShowProcessesCpuResultFromReader(s io.Reader) (*ShowProcessesCpuResponseResult, error) {
+	ShowProcessesCpuResponseResultDat := &ShowProcessesCpuResponseResult{}
+	jsonDec := json.NewDecoder(s)
+	jsonDec.UseAutoConvert()
+	jsonDec.UseSlice()
+	err := jsonDec.Decode(ShowProcessesCpuResponseResultDat)
+	if err != nil {
+		return nil, fmt.Errorf("parsing error: %s", err)
+	}
+	return ShowProcessesCpuResponseResultDat, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//            and Paul Schou     (github.com/pschou)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseShowProcessesCpuJsonOutput(t *testing.T) {
+	outputDir := "../../assets/requests"
+	fp := fmt.Sprintf("%s/resp.show.processes.cpu.1.json", outputDir)
+	content, err := ioutil.ReadFile(fp)
+	if err != nil {
+		t.Fatalf("failed reading '%s', error: %v", fp, err)
+	}
+	// The fixture is a JSON-RPC response.
+	var resp JSONRPCResponse
+	if err := json.Unmarshal(content, &resp); err != nil {
+		t.Fatalf("failed parsing '%s', error: %v", fp, err)
+	}
+	dat, err := NewShowProcessesCpuResultFromBytes(resp.Result)
+	if err != nil {
+		t.Fatalf("failed parsing '%s', error: %v", fp, err)
+	}
+
+	if dat.Body.IdlePercent != 91.56 || dat.Body.KernelPercent != 1.79 || dat.Body.UserPercent != 6.64 {
+		t.Fatalf("unexpected totals: idle %v, kernel %v, user %v", dat.Body.IdlePercent, dat.Body.KernelPercent, dat.Body.UserPercent)
+	}
+	flat := dat.Flat()
+	if len(flat) != 288 {
+		t.Fatalf("expected 288 processes, got %d", len(flat))
+	}
+	exp := ShowProcessesCpuResultFlat{
+		PID:     1,
+		Process: "init",
+		Runtime: Duration(167793 * time.Millisecond),
+		Invoked: 7415440,
+		Usecs:   22,
+	}
+	if !reflect.DeepEqual(exp, flat[0]) {
+		t.Fatalf("expected:\n%#v\ngot:\n%#v", exp, flat[0])
+	}
+
+	var top []string
+	for _, p := range dat.TopN(3) {
+		top = append(top, p.Process)
+	}
+	// t2usd is at 5%, nsusd leads those at 1% by its runtime.
+	if expTop := []string{"t2usd", "nsusd", "event_manager"}; !reflect.DeepEqual(expTop, top) {
+		t.Fatalf("expected top %v, got %v", expTop, top)
+	}
+	if n := len(dat.TopN(0)); n != 288 {
+		t.Fatalf("expected 288 processes, got %d", n)
+	}
+}
+
+func TestShowProcessesCpuDelta(t *testing.T) {
+	prev, err := NewShowProcessesCpuResultFromString(`{"body": {"TABLE_process_cpu": {"ROW_process_cpu": [
+		{"pid": 1, "process": "init", "runtime": 1000, "invoked": 10, "usecs": 100, "onesec": "0.00"},
+		{"pid": 2, "process": "bgp", "runtime": 5000, "invoked": 50, "usecs": 100, "onesec": "0.00"},
+		{"pid": 3, "process": "ospf", "runtime": 9000, "invoked": 90, "usecs": 100, "onesec": "0.00"}
+	]}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur, err := NewShowProcessesCpuResultFromString(`{"body": {"TABLE_process_cpu": {"ROW_process_cpu": [
+		{"pid": 1, "process": "init", "runtime": 1100, "invoked": 11, "usecs": 100, "onesec": "0.00"},
+		{"pid": 2, "process": "bgp", "runtime": 13000, "invoked": 80, "usecs": 266, "onesec": "80.00"},
+		{"pid": 3, "process": "isis", "runtime": 500, "invoked": 5, "usecs": 100, "onesec": "0.00"}
+	]}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []ShowProcessesCpuDelta{
+		{PID: 2, Process: "bgp", Runtime: Duration(8 * time.Second), Invoked: 30, Percent: 80},
+		{PID: 3, Process: "isis", Runtime: Duration(500 * time.Millisecond), Invoked: 5, Percent: 5},
+		{PID: 1, Process: "init", Runtime: Duration(100 * time.Millisecond), Invoked: 1, Percent: 1},
+	}
+	if delta := cur.Delta(prev, 10*time.Second); !reflect.DeepEqual(exp, delta) {
+		t.Fatalf("expected:\n%#v\ngot:\n%#v", exp, delta)
+	}
+
+	// The same, on the NX-OS API responses.
+	prevResp, curResp := &ShowProcessesCpuResponse{}, &ShowProcessesCpuResponse{}
+	prevResp.InsAPI.Outputs.Output = *prev
+	curResp.InsAPI.Outputs.Output = *cur
+	if delta := curResp.Delta(prevResp, 10*time.Second); !reflect.DeepEqual(exp, delta) {
+		t.Fatalf("expected:\n%#v\ngot:\n%#v", exp, delta)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/pschou/go-json"
+	"io"
+	"strings"
+)
+
+// Batch is a set of show commands sent to the API in a single JSON-RPC
+// request. Each command has its own target and error.
+//
+//	var vpc client.ShowVpcResponseResult
+//	var arp client.ShowIpArpResponseResult
+//	err := cli.Batch().Add("show vpc", &vpc).Add("show ip arp", &arp).Do()
+type Batch struct {
+	cli   *Client
+	items []*BatchItem
+}
+
+// BatchItem is a command of a Batch.
+type BatchItem struct {
+	Command  string
+	Target   interface{}
+	Response *JSONRPCResponse
+	Err      error
+	decode   func([]byte) error
+}
+
+// BatchError is returned by Batch.Do when one or more commands of the batch
+// failed. The errors of the individual commands are in the Items.
+type BatchError struct {
+	Items []*BatchItem
+}
+
+func (e *BatchError) Error() string {
+	var msgs []string
+	for _, item := range e.Items {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", item.Command, item.Err))
+	}
+	return fmt.Sprintf("%d of the batch commands failed: %s", len(e.Items), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed commands.
+func (e *BatchError) Unwrap() []error {
+	var errs []error
+	for _, item := range e.Items {
+		errs = append(errs, item.Err)
+	}
+	return errs
+}
+
+// Batch returns an empty Batch of the client.
+func (cli *Client) Batch() *Batch {
+	return &Batch{cli: cli}
+}
+
+// Add queues the command. The "result" of the command's JSON-RPC response
+// is decoded into target, which is typically a pointer to one of the
+// *ResponseResult types, e.g. *ShowVpcResponseResult. Use a nil target to
+// only check the command for errors.
+func (b *Batch) Add(cmd string, target interface{}) *Batch {
+	item := &BatchItem{Command: cmd, Target: target}
+	item.decode = func([]byte) error {
+		if target == nil {
+			return nil
+		}
+		return decodeResult(item.Response.Result, target)
+	}
+	b.items = append(b.items, item)
+	return b
+}
+
+// AddFunc queues the command. The JSON-RPC response of the command is
+// passed to fn, e.g. to parse it with NewSysInfoFromBytes.
+func (b *Batch) AddFunc(cmd string, fn func([]byte) error) *Batch {
+	b.items = append(b.items, &BatchItem{Command: cmd, decode: fn})
+	return b
+}
+
+// Items returns the commands of the batch.
+func (b *Batch) Items() []*BatchItem {
+	return b.items
+}
+
+// Do sends the batch to the API.
+func (b *Batch) Do() error {
+	return b.DoContext(context.Background())
+}
+
+// DoContext is like Do, but the request is bound to the provided context.
+// It returns an error when the request fails as a whole, or *BatchError
+// when some of the commands fail.
+func (b *Batch) DoContext(ctx context.Context) error {
+	if len(b.items) == 0 {
+		return fmt.Errorf("empty input")
+	}
+	var cmds []string
+	for _, item := range b.items {
+		cmds = append(cmds, item.Command)
+	}
+	resp, err := b.cli.callJSONRPC(ctx, cmds)
+	if err != nil {
+		return err
+	}
+	elems, err := splitJSONRPCResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	batchErr := &BatchError{}
+	for i, item := range b.items {
+		item.Response, item.Err = nil, nil
+		elem, found := elems[uint64(i+1)]
+		if !found {
+			item.Err = fmt.Errorf("no response for command id %d", i+1)
+		} else {
+			item.Response = elem.response
+			if err := item.Response.Err(); err != nil {
+				item.Err = err
+			} else if err := item.decode(elem.raw); err != nil {
+				item.Err = err
+			}
+		}
+		if item.Err != nil {
+			batchErr.Items = append(batchErr.Items, item)
+		}
+	}
+	if len(batchErr.Items) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+type jsonRPCResponseElement struct {
+	response *JSONRPCResponse
+	raw      []byte
+}
+
+// splitJSONRPCResponse returns the elements of a JSON-RPC response by
+// their IDs. A single object, which NX-API returns for a single command,
+// is treated as an array of one.
+func splitJSONRPCResponse(s []byte) (map[uint64]*jsonRPCResponseElement, error) {
+	var raws []json.RawMessage
+	s = bytes.TrimSpace(s)
+	if len(s) > 0 && s[0] == '{' {
+		raws = append(raws, json.RawMessage(s))
+	} else if err := json.Unmarshal(s, &raws); err != nil {
+		return nil, fmt.Errorf("parsing error: %s, server response: %s", err, string(s[:]))
+	}
+	elems := make(map[uint64]*jsonRPCResponseElement)
+	for i, raw := range raws {
+		resp := &JSONRPCResponse{}
+		if err := json.Unmarshal(raw, resp); err != nil {
+			return nil, fmt.Errorf("parsing error: %s, server response: %s", err, string(raw[:]))
+		}
+		id := resp.ID
+		if id == 0 {
+			// Error responses, e.g. "Invalid request", carry no id.
+			id = uint64(i + 1)
+		}
+		elems[id] = &jsonRPCResponseElement{response: resp, raw: raw}
+	}
+	return elems, nil
+}
+
+// decodeResult decodes a structured command output into target.
+func decodeResult(s []byte, target interface{}) error {
+	if len(s) == 0 {
+		return fmt.Errorf("missing result")
+	}
+	if err := newResultDecoder(bytes.NewReader(s), target).Decode(target); err != nil {
+		return fmt.Errorf("parsing error: %s", err)
+	}
+	return nil
+}
+
+// newResultDecoder returns a decoder of a structured command output into
+// target, with the options of the parser of the command, so that e.g. a
+// *ShowVersionResponseResult decodes as with NewShowVersionResultFromBytes.
+// The empty objects NX-OS returns for values with no data, e.g.
+// "package_id": {}, are ignored.
+func newResultDecoder(r io.Reader, target interface{}) *json.Decoder {
+	jsonDec := json.NewDecoder(r)
+	switch target.(type) {
+	case *ShowEnvironmentResponse, *ShowEnvironmentResponseResult, *ShowEnvironmentResultBody:
+		jsonDec.UseAutoConvertWithTrimSpace()
+		jsonDec.UseAutoTrimSpace()
+	default:
+		jsonDec.UseAutoConvert()
+	}
+	jsonDec.UseSlice()
+	jsonDec.IgnoreEmptyObject()
+	return jsonDec
+}
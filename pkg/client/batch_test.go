@@ -0,0 +1,137 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClientBatch(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		dataDir := "../../assets/requests"
+		resultFileMap := map[string]string{
+			"show version":      "resp.result.show.version.json",
+			"show bgp sessions": "resp.result.show.bgp.sessions.json",
+			"show ip arp":       "resp.result.show.ip.arp.json",
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		var j []*JSONRPCRequest
+		if err := json.Unmarshal(body, &j); err != nil {
+			http.Error(w, fmt.Sprintf("Bad Request, json.Unmarshal: %s", err), http.StatusBadRequest)
+			return
+		}
+		var resp []json.RawMessage
+		for _, r := range j {
+			fn, found := resultFileMap[r.Params.Command]
+			if !found {
+				resp = append(resp, json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","error":{"code":-32602,`+
+					`"message":"Invalid params","data":{"msg":"Input CLI command error"}},"id":%d}`, r.ID)))
+				continue
+			}
+			fc, err := ioutil.ReadFile(dataDir + "/" + fn)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			resp = append(resp, json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","result":%s,"id":%d}`, fc, r.ID)))
+		}
+		// NX-API does not guarantee the order of the responses.
+		for i, k := 0, len(resp)-1; i < k; i, k = i+1, k-1 {
+			resp[i], resp[k] = resp[k], resp[i]
+		}
+		out, _ := json.Marshal(resp)
+		w.Write(out)
+	}))
+	defer server.Close()
+
+	cli := newTestClient(server.URL)
+	var sysinfo *SysInfo
+	var bgp ShowBgpSessionsResponseResult
+	var arp ShowIpArpResponseResult
+	err := cli.Batch().
+		Add("show bgp sessions", &bgp).
+		Add("show ip arp", &arp).
+		AddFunc("show version", func(b []byte) (err error) {
+			sysinfo, err = NewSysInfoFromBytes(b)
+			return
+		}).
+		Do()
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if requests != 1 {
+		t.Fatalf("client: expected a single request, got %d", requests)
+	}
+	if len(bgp.Flat()) == 0 {
+		t.Fatalf("client: expected bgp sessions, got none")
+	}
+	if len(arp.Flat()) != 7 {
+		t.Fatalf("client: expected 7 arp entries, got %d", len(arp.Flat()))
+	}
+	if sysinfo == nil || sysinfo.Hostname != "macsec2" {
+		t.Fatalf("client: unexpected show version result: %#v", sysinfo)
+	}
+
+	// The typed results decode as with their parsers, e.g. the empty
+	// "package_id" object of show version.
+	var version ShowVersionResponseResult
+	if err := cli.Batch().Add("show version", &version).Do(); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	fc, err := ioutil.ReadFile("../../assets/requests/resp.result.show.version.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expVersion, err := NewShowVersionResultFromBytes(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(version.Body, expVersion.Body) || version.Body.HostName != "macsec2" {
+		t.Fatalf("client: unexpected show version result: %#v", version.Body)
+	}
+
+	batch := cli.Batch().Add("show ip arp", &arp).Add("show foo", nil)
+	err = batch.Do()
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Items) != 1 || batchErr.Items[0].Command != "show foo" {
+		t.Fatalf("client: expected a batch error for 'show foo', got: %v", err)
+	}
+	if !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("client: expected %v, got: %v", ErrInvalidCommand, err)
+	}
+	if errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("client: unexpected match of the batch error: %v", err)
+	}
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32602 {
+		t.Fatalf("client: expected an RPC error for 'show foo', got: %v", err)
+	}
+	if batch.Items()[0].Err != nil {
+		t.Fatalf("client: unexpected error for 'show ip arp': %s", batch.Items()[0].Err)
+	}
+
+	if err := cli.Batch().Do(); err == nil {
+		t.Fatalf("client: expected error for an empty batch")
+	}
+}
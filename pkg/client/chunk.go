@@ -0,0 +1,75 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// endOfChunks is the session ID NX-API returns with the last chunk.
+const endOfChunks = "eoc"
+
+// CliShowASCIIChunked runs the show command in chunk mode and writes its
+// text output to w as the chunks arrive, e.g. for "show running-config" or
+// "show tech-support" on a large chassis. It returns the number of bytes
+// written.
+func (cli *Client) CliShowASCIIChunked(ctx context.Context, cmd string, w io.Writer) (int64, error) {
+	return cli.InsAPIChunked(ctx, "cli_show_ascii", cmd, w)
+}
+
+// InsAPIChunked sends the command in an NX-OS API request of the given type
+// with chunk mode enabled. It follows the session ID of the responses until
+// the device signals the end of the output, and writes each chunk to w, so
+// that the output never needs to be held in memory as a whole.
+func (cli *Client) InsAPIChunked(ctx context.Context, t, cmd string, w io.Writer) (int64, error) {
+	var written int64
+	sid := "1"
+	for {
+		b, err := cli.callAPI(ctx, &Request{InsAPI: NewInsAPIChunkRequest(cmd, t, sid)})
+		if err != nil {
+			return written, err
+		}
+		resp, err := NewInsAPIResponseFromBytes(b)
+		if err != nil {
+			return written, err
+		}
+		if len(resp.InsAPI.Outputs.Output) != 1 {
+			return written, fmt.Errorf("expected a single output in chunk %s, got %d", sid, len(resp.InsAPI.Outputs.Output))
+		}
+		output := resp.InsAPI.Outputs.Output[0]
+		if err := output.Err(); err != nil {
+			return written, err
+		}
+		chunk := []byte(output.Text())
+		if len(chunk) == 0 && len(output.Body) > 0 && output.Body[0] != '"' {
+			chunk = output.Body
+		}
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		next := resp.InsAPI.Sid
+		if next == "" || next == endOfChunks {
+			return written, nil
+		}
+		if next == sid {
+			return written, fmt.Errorf("chunk session %s did not advance", sid)
+		}
+		sid = next
+	}
+}
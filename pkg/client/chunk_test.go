@@ -0,0 +1,75 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestClientChunked(t *testing.T) {
+	var config strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&config, "interface Ethernet1/%d\n  description port %d\n", i, i)
+	}
+	chunkSize := 10000
+	var sids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		var j *InsAPIRequest
+		if err := json.Unmarshal(body, &j); err != nil {
+			http.Error(w, fmt.Sprintf("Bad Request, json.Unmarshal: %s", err), http.StatusBadRequest)
+			return
+		}
+		if j.Params.Chunk != "1" || j.Params.Input != "show running-config" {
+			http.Error(w, fmt.Sprintf("Bad Request, unexpected request: %s", body), http.StatusBadRequest)
+			return
+		}
+		sids = append(sids, j.Params.ID)
+		n, _ := strconv.Atoi(j.Params.ID)
+		start, end := (n-1)*chunkSize, n*chunkSize
+		next := strconv.Itoa(n + 1)
+		if end >= config.Len() {
+			end = config.Len()
+			next = "eoc"
+		}
+		chunk, _ := json.Marshal(config.String()[start:end])
+		fmt.Fprintf(w, `{"ins_api":{"type":"cli_show_ascii","version":"1.0","sid":"%s",`+
+			`"outputs":{"output":{"input":"show running-config","msg":"Success","code":"200","body":%s}}}}`, next, chunk)
+	}))
+	defer server.Close()
+
+	cli := newTestClient(server.URL)
+	var out bytes.Buffer
+	n, err := cli.CliShowASCIIChunked(context.Background(), "show running-config", &out)
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if n != int64(config.Len()) || out.String() != config.String() {
+		t.Fatalf("client: reassembled output mismatch, expected %d bytes, got %d", config.Len(), n)
+	}
+	expChunks := (config.Len() + chunkSize - 1) / chunkSize
+	if len(sids) != expChunks || sids[0] != "1" || sids[1] != "2" {
+		t.Fatalf("client: expected %d chunk requests, got session IDs: %v", expChunks, sids)
+	}
+}
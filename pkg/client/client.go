@@ -16,13 +16,18 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -42,15 +47,12 @@ type JSONRPCRequestParameters struct {
 
 // JSONRPCResponseErrorData defines error message in error response
 type JSONRPCResponseErrorData struct {
-	Msg string `json:msg`
+	Msg string `json:"msg" xml:"msg"`
 }
 
-// JSONRPCResponseError defines JSON RPC error response
-type JSONRPCResponseError struct {
-	Code    int64                    `json:code`
-	Message string                   `json:message`
-	Data    JSONRPCResponseErrorData `json:data`
-}
+// JSONRPCResponseError defines JSON RPC error response. It is the same type
+// as RPCError.
+type JSONRPCResponseError = RPCError
 
 // JSONRPCResponseBody defines JSON RPC normal response body
 type JSONRPCResponseBody struct {
@@ -60,11 +62,19 @@ type JSONRPCResponseBody struct {
 // JSONRPCResponse is the payload of JSON RPC response to the API.
 type JSONRPCResponse struct {
 	Version string                `json:"jsonrpc" xml:"jsonrpc"`
-	Result  json.RawMessage       `json:"result, omitempty" xml:"result"`
-	Error   *JSONRPCResponseError `json:"error, omitempty" xml:"error"`
+	Result  json.RawMessage       `json:"result,omitempty" xml:"result"`
+	Error   *JSONRPCResponseError `json:"error,omitempty" xml:"error"`
 	ID      uint64                `json:"id" xml:"id"`
 }
 
+// Err returns the error of the response, or nil if the command succeeded.
+func (r *JSONRPCResponse) Err() error {
+	if r.Error == nil {
+		return nil
+	}
+	return r.Error
+}
+
 // NewJSONRPCRequest returns an instance of JSONRPCRequest.
 func NewJSONRPCRequest(cmds []string) []*JSONRPCRequest {
 	var arr []*JSONRPCRequest
@@ -107,7 +117,17 @@ func NewInsAPIRequest(s, t string) *InsAPIRequest {
 	r.Params.Chunk = "0"
 	r.Params.ID = "1"
 	r.Params.Input = s
-	r.Params.Format = "json"
+	r.Params.Format = OutputFormatJSON
+	return r
+}
+
+// NewInsAPIChunkRequest returns an instance of InsAPIRequest for a chunk of
+// the output of the command. The sid is the session ID returned with the
+// previous chunk, or "1" for the first one.
+func NewInsAPIChunkRequest(s, t, sid string) *InsAPIRequest {
+	r := NewInsAPIRequest(s, t)
+	r.Params.Chunk = "1"
+	r.Params.ID = sid
 	return r
 }
 
@@ -117,6 +137,30 @@ func NewInsAPICliShowASCIIRequest(s string) *InsAPIRequest {
 	return NewInsAPIRequest(s, "cli_show_ascii")
 }
 
+// NewInsAPICliShowRequest returns an instance of InsAPIRequest for cli_show
+// type of NX-OS API request.
+func NewInsAPICliShowRequest(s string) *InsAPIRequest {
+	return NewInsAPIRequest(s, "cli_show")
+}
+
+// NewInsAPICliShowArrayRequest returns an instance of InsAPIRequest for
+// cli_show_array type of NX-OS API request.
+func NewInsAPICliShowArrayRequest(s string) *InsAPIRequest {
+	return NewInsAPIRequest(s, "cli_show_array")
+}
+
+// NewInsAPICliConfRequest returns an instance of InsAPIRequest for cli_conf
+// type of NX-OS API request.
+func NewInsAPICliConfRequest(s string) *InsAPIRequest {
+	return NewInsAPIRequest(s, "cli_conf")
+}
+
+// NewInsAPIBashRequest returns an instance of InsAPIRequest for bash type
+// of NX-OS API request.
+func NewInsAPIBashRequest(s string) *InsAPIRequest {
+	return NewInsAPIRequest(s, "bash")
+}
+
 // Client is an instance of Cisco NX-OS API client.
 type Client struct {
 	host     string
@@ -125,13 +169,69 @@ type Client struct {
 	username string
 	password string
 	secure   bool
+
+	// Credential provider superseding username and password, see
+	// credentials.go.
+	credentialProvider CredentialProvider
+
+	// TLS settings, see tls.go.
+	rootCAs      *x509.CertPool
+	serverName   string
+	certificates []tls.Certificate
+
+	// NX-API session, see session.go.
+	sessionMu      sync.Mutex
+	session        *http.Cookie
+	sessionExpires time.Time
+	noSession      bool
+
+	// HTTP transport settings, see transport.go.
+	mu                  sync.Mutex
+	httpClient          *http.Client
+	customHTTPClient    bool
+	dialTimeout         time.Duration
+	tlsHandshakeTimeout time.Duration
+	idleConnTimeout     time.Duration
+	timeout             time.Duration
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	dialContext         func(ctx context.Context, network, addr string) (net.Conn, error)
+	proxy               func(*http.Request) (*url.URL, error)
+	unixSocket          string
+	ssh                 *sshTransport
+
+	// Retry policy of show commands, see retry.go.
+	retryPolicy *RetryPolicy
+
+	// Concurrency and rate limits, see limit.go.
+	limitMu     sync.Mutex
+	inFlight    chan struct{}
+	rateLimiter *rateLimiter
+
+	// Middleware chain, see middleware.go.
+	middlewareMu sync.Mutex
+	middleware   []Middleware
+
+	logger Logger
+
+	// NX-API REST client, see dme.go.
+	dmeOnce sync.Once
+	dme     *DME
 }
 
 // NewClient returns an instance of Client.
 func NewClient() *Client {
 	return &Client{
-		port:     443,
-		protocol: "https",
+		port:                443,
+		protocol:            "https",
+		dialTimeout:         defaultDialTimeout,
+		tlsHandshakeTimeout: defaultTLSHandshakeTimeout,
+		idleConnTimeout:     defaultIdleConnTimeout,
+		timeout:             defaultTimeout,
+		maxIdleConns:        defaultMaxIdleConns,
+		maxIdleConnsPerHost: defaultMaxIdleConnsPerHost,
+		proxy:               http.ProxyFromEnvironment,
+		retryPolicy:         DefaultRetryPolicy(),
 	}
 }
 
@@ -141,6 +241,7 @@ func (cli *Client) SetHost(s string) error {
 		return fmt.Errorf("empty hostname or ip address")
 	}
 	cli.host = s
+	cli.ClearSession()
 	return nil
 }
 
@@ -150,6 +251,7 @@ func (cli *Client) SetPort(p int) error {
 		return fmt.Errorf("invalid port: %d", p)
 	}
 	cli.port = p
+	cli.ClearSession()
 	return nil
 }
 
@@ -159,6 +261,7 @@ func (cli *Client) SetUsername(s string) error {
 		return fmt.Errorf("empty username")
 	}
 	cli.username = s
+	cli.ClearSession()
 	return nil
 }
 
@@ -168,6 +271,7 @@ func (cli *Client) SetPassword(s string) error {
 		return fmt.Errorf("empty password")
 	}
 	cli.password = s
+	cli.ClearSession()
 	return nil
 }
 
@@ -188,96 +292,177 @@ func (cli *Client) SetProtocol(s string) error {
 // and check certificate errors.
 func (cli *Client) SetSecure() error {
 	cli.secure = true
+	cli.resetTransport()
 	return nil
 }
 
-func callAPI(contentType string, url string, payload []byte, username, password string, secure bool) ([]byte, error) {
-	tr := &http.Transport{
-		Dial: (&net.Dialer{
-			Timeout: 10 * time.Second,
-		}).Dial,
-		TLSHandshakeTimeout: 10 * time.Second,
+// callAPIOnce sends the request in a single attempt, re-authenticating when
+// the session cookie is rejected.
+func (cli *Client) callAPIOnce(ctx context.Context, r *Request) ([]byte, error) {
+	release, err := cli.acquire(ctx)
+	if err != nil {
+		return nil, err
 	}
-	if !secure {
-		tr.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: true,
-		}
+	defer release()
+	r.Host = cli.host
+	r.session = cli.sessionCookie()
+	resp, err := cli.roundTrip(ctx, r)
+	if r.session != nil && errors.Is(err, ErrUnauthorized) {
+		// The session has expired on the device, re-authenticate.
+		cli.ClearSession()
+		r.session = nil
+		resp, err = cli.roundTrip(ctx, r)
 	}
-	cli := &http.Client{
-		Transport: tr,
-		Timeout:   time.Second * 30,
+	if err != nil {
+		return nil, err
 	}
+	return resp.Body, nil
+}
+
+// send performs a single POST request. When the session of the request is
+// nil, the request carries the credentials of the client, otherwise the
+// session cookie. The response is returned along with *HTTPError, if any.
+func (cli *Client) send(ctx context.Context, r *Request) (*Response, error) {
+	url := cli.url()
 	var reqContentType string
-	switch contentType {
+	switch r.ContentType() {
 	case "jsonrpc":
 		reqContentType = "application/json-rpc"
 	case "json":
 		reqContentType = "application/json"
+	case "xml":
+		reqContentType = "application/xml"
 	default:
-		return nil, fmt.Errorf("unsupported content type: %s", contentType)
+		return nil, fmt.Errorf("unsupported content type: %s", r.ContentType())
 	}
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
+	payload, err := r.Payload()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("Content-Type", reqContentType)
 	req.Header.Add("Cache-Control", "no-cache")
-	req.SetBasicAuth(username, password)
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+	if r.session != nil {
+		req.AddCookie(r.session)
+	} else if cli.unixSocket != "" {
+		req.AddCookie(cli.localSessionCookie())
+	} else {
+		creds, err := cli.credentials(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Without a username, the client authenticates with its
+		// certificate only.
+		if creds.Username != "" {
+			req.SetBasicAuth(creds.Username, creds.Password)
+		}
+	}
 
-	res, err := cli.Do(req)
+	res, err := cli.getHTTPClient().Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		if !strings.HasSuffix(err.Error(), "EOF") {
 			return nil, err
 		}
 	}
 	if res == nil {
+		if err != nil {
+			return nil, fmt.Errorf("response: <nil>, verify url: %s: %w", url, err)
+		}
 		return nil, fmt.Errorf("response: <nil>, verify url: %s", url)
 	}
 	defer res.Body.Close()
+	cli.updateSession(res)
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		if err.Error() != "EOF" {
 			return nil, err
 		}
 	}
-	if len(body) < 500 {
-		if bytes.Contains(body, []byte("401 Authorization Required")) {
-			return nil, fmt.Errorf("401 Authorization Required")
-		}
-		if bytes.Contains(body, []byte("405 Not Allowed")) {
-			return nil, fmt.Errorf("405 Not Allowed")
-		}
-		if bytes.Contains(body, []byte("Server internal error")) {
-			return nil, fmt.Errorf("500 Server Internal Error")
-		}
+	resp := &Response{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Header:     res.Header,
+		Body:       body,
 	}
-	return body, nil
+	if err := newHTTPError(res, body); err != nil {
+		return resp, err
+	}
+	return resp, nil
 }
 
-// GetSystemInfo returns information about the system ("show version").
-func (cli *Client) GetSystemInfo() (*SysInfo, error) {
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-	req := NewJSONRPCRequest([]string{"show version"})
-	payload, err := json.Marshal(req)
+func (cli *Client) url() string {
+	return fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
+}
+
+// callJSONRPC sends the commands in a single JSON-RPC request and returns
+// the raw response.
+func (cli *Client) callJSONRPC(ctx context.Context, cmds []string) ([]byte, error) {
+	return cli.callAPI(ctx, &Request{JSONRPC: NewJSONRPCRequest(cmds)})
+}
+
+// callShow sends a single show command in a JSON-RPC request and returns
+// the raw response. A JSON-RPC error in the response is returned as
+// *RPCError.
+func (cli *Client) callShow(ctx context.Context, cmd string) ([]byte, error) {
+	resp, err := cli.callJSONRPC(ctx, []string{cmd})
 	if err != nil {
 		return nil, err
 	}
-	resp, err := callAPI("jsonrpc", url, payload, cli.username, cli.password, cli.secure)
+	if err := newRPCError(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// callInsAPI sends the NX-OS API request and returns the raw response. An
+// error reported in the output of the response is returned as *RPCError.
+func (cli *Client) callInsAPI(ctx context.Context, req *InsAPIRequest) ([]byte, error) {
+	resp, err := cli.callAPI(ctx, &Request{InsAPI: req})
 	if err != nil {
 		return nil, err
 	}
-	return NewSysInfoFromBytes(resp)
+	if err := newInsAPIError(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
-// GetVlans returns vlan information ("show vlan").
-func (cli *Client) GetVlans() ([]*Vlan, error) {
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-	req := NewJSONRPCRequest([]string{"show vlan"})
-	payload, err := json.Marshal(req)
+// GetSystemInfo returns information about the system ("show version").
+func (cli *Client) GetSystemInfo() (*SysInfo, error) {
+	return cli.GetSystemInfoContext(context.Background())
+}
+
+// GetSystemInfoContext is like GetSystemInfo, but the request is bound to
+// the provided context.
+func (cli *Client) GetSystemInfoContext(ctx context.Context) (*SysInfo, error) {
+	resp, err := cli.callShow(ctx, "show version")
 	if err != nil {
 		return nil, err
 	}
-	resp, err := callAPI("jsonrpc", url, payload, cli.username, cli.password, cli.secure)
+	return NewSysInfoFromBytes(resp)
+}
+
+// GetVlans returns vlan information ("show vlan").
+func (cli *Client) GetVlans() ([]*Vlan, error) {
+	return cli.GetVlansContext(context.Background())
+}
+
+// GetVlansContext is like GetVlans, but the request is bound to the provided
+// context.
+func (cli *Client) GetVlansContext(ctx context.Context) ([]*Vlan, error) {
+	resp, err := cli.callShow(ctx, "show vlan")
 	if err != nil {
 		return nil, err
 	}
@@ -286,13 +471,13 @@ func (cli *Client) GetVlans() ([]*Vlan, error) {
 
 // GetInterfaces returns interface information ("show interface").
 func (cli *Client) GetInterfaces() ([]*Interface, error) {
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-	req := NewJSONRPCRequest([]string{"show interface"})
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := callAPI("jsonrpc", url, payload, cli.username, cli.password, cli.secure)
+	return cli.GetInterfacesContext(context.Background())
+}
+
+// GetInterfacesContext is like GetInterfaces, but the request is bound to
+// the provided context.
+func (cli *Client) GetInterfacesContext(ctx context.Context) ([]*Interface, error) {
+	resp, err := cli.callShow(ctx, "show interface")
 	if err != nil {
 		return nil, err
 	}
@@ -301,13 +486,13 @@ func (cli *Client) GetInterfaces() ([]*Interface, error) {
 
 // GetInterface returns interface information ("show interface <name>").
 func (cli *Client) GetInterface(name string) (*Interface, error) {
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-	req := NewJSONRPCRequest([]string{"show interface " + name})
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := callAPI("jsonrpc", url, payload, cli.username, cli.password, cli.secure)
+	return cli.GetInterfaceContext(context.Background(), name)
+}
+
+// GetInterfaceContext is like GetInterface, but the request is bound to the
+// provided context.
+func (cli *Client) GetInterfaceContext(ctx context.Context, name string) (*Interface, error) {
+	resp, err := cli.callShow(ctx, "show interface "+name)
 	if err != nil {
 		return nil, err
 	}
@@ -319,13 +504,13 @@ func (cli *Client) GetInterface(name string) (*Interface, error) {
 
 // GetSystemResources returns SystemResources instance ("show system resources").
 func (cli *Client) GetSystemResources() (*SystemResources, error) {
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-	req := NewJSONRPCRequest([]string{"show system resources"})
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := callAPI("jsonrpc", url, payload, cli.username, cli.password, cli.secure)
+	return cli.GetSystemResourcesContext(context.Background())
+}
+
+// GetSystemResourcesContext is like GetSystemResources, but the request is
+// bound to the provided context.
+func (cli *Client) GetSystemResourcesContext(ctx context.Context) (*SystemResources, error) {
+	resp, err := cli.callShow(ctx, "show system resources")
 	if err != nil {
 		return nil, err
 	}
@@ -334,13 +519,13 @@ func (cli *Client) GetSystemResources() (*SystemResources, error) {
 
 // GetSystemEnvironment returns SystemEnvironment instance ("show environment").
 func (cli *Client) GetSystemEnvironment() (*SystemEnvironment, error) {
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-	req := NewJSONRPCRequest([]string{"show environment"})
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := callAPI("jsonrpc", url, payload, cli.username, cli.password, cli.secure)
+	return cli.GetSystemEnvironmentContext(context.Background())
+}
+
+// GetSystemEnvironmentContext is like GetSystemEnvironment, but the request
+// is bound to the provided context.
+func (cli *Client) GetSystemEnvironmentContext(ctx context.Context) (*SystemEnvironment, error) {
+	resp, err := cli.callShow(ctx, "show environment")
 	if err != nil {
 		return nil, err
 	}
@@ -349,13 +534,13 @@ func (cli *Client) GetSystemEnvironment() (*SystemEnvironment, error) {
 
 // GetGeneric returns the output of a particular command.
 func (cli *Client) GetGeneric(s string) ([]byte, error) {
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-	req := NewJSONRPCRequest([]string{s})
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := callAPI("jsonrpc", url, payload, cli.username, cli.password, cli.secure)
+	return cli.GetGenericContext(context.Background(), s)
+}
+
+// GetGenericContext is like GetGeneric, but the request is bound to the
+// provided context.
+func (cli *Client) GetGenericContext(ctx context.Context, s string) ([]byte, error) {
+	resp, err := cli.callShow(ctx, s)
 	if err != nil {
 		return nil, err
 	}
@@ -364,13 +549,13 @@ func (cli *Client) GetGeneric(s string) ([]byte, error) {
 
 // GetBgpSummary returns BgpSummary instance ("show ip bgp summary vrf all").
 func (cli *Client) GetBgpSummary() (*BgpSummary, error) {
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-	req := NewInsAPICliShowASCIIRequest("show ip bgp summary vrf all")
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := callAPI("json", url, payload, cli.username, cli.password, cli.secure)
+	return cli.GetBgpSummaryContext(context.Background())
+}
+
+// GetBgpSummaryContext is like GetBgpSummary, but the request is bound to
+// the provided context.
+func (cli *Client) GetBgpSummaryContext(ctx context.Context) (*BgpSummary, error) {
+	resp, err := cli.callInsAPI(ctx, NewInsAPICliShowASCIIRequest("show ip bgp summary vrf all"))
 	if err != nil {
 		return nil, err
 	}
@@ -380,23 +565,29 @@ func (cli *Client) GetBgpSummary() (*BgpSummary, error) {
 // GetRunningConfiguration returns Configuration instance for running
 // configuration ("show running-config").
 func (cli *Client) GetRunningConfiguration() (*Configuration, error) {
-	return cli.getConfiguration("running")
+	return cli.getConfiguration(context.Background(), "running")
+}
+
+// GetRunningConfigurationContext is like GetRunningConfiguration, but the
+// request is bound to the provided context.
+func (cli *Client) GetRunningConfigurationContext(ctx context.Context) (*Configuration, error) {
+	return cli.getConfiguration(ctx, "running")
 }
 
 // GetStartupConfiguration returns Configuration instance for startup
 // configuration ("show startup-config").
 func (cli *Client) GetStartupConfiguration() (*Configuration, error) {
-	return cli.getConfiguration("startup")
+	return cli.getConfiguration(context.Background(), "startup")
 }
 
-func (cli *Client) getConfiguration(s string) (*Configuration, error) {
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-	req := NewInsAPICliShowASCIIRequest("show " + s + "-config")
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := callAPI("json", url, payload, cli.username, cli.password, cli.secure)
+// GetStartupConfigurationContext is like GetStartupConfiguration, but the
+// request is bound to the provided context.
+func (cli *Client) GetStartupConfigurationContext(ctx context.Context) (*Configuration, error) {
+	return cli.getConfiguration(ctx, "startup")
+}
+
+func (cli *Client) getConfiguration(ctx context.Context, s string) (*Configuration, error) {
+	resp, err := cli.callInsAPI(ctx, NewInsAPICliShowASCIIRequest("show "+s+"-config"))
 	if err != nil {
 		return nil, err
 	}
@@ -406,13 +597,13 @@ func (cli *Client) getConfiguration(s string) (*Configuration, error) {
 // GetTransceivers returns data about transceivers attached to Interface
 // ("show interface transceiver details").
 func (cli *Client) GetTransceivers() ([]*Transceiver, error) {
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-	req := NewJSONRPCRequest([]string{"show interface transceiver details"})
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := callAPI("jsonrpc", url, payload, cli.username, cli.password, cli.secure)
+	return cli.GetTransceiversContext(context.Background())
+}
+
+// GetTransceiversContext is like GetTransceivers, but the request is bound
+// to the provided context.
+func (cli *Client) GetTransceiversContext(ctx context.Context) ([]*Transceiver, error) {
+	resp, err := cli.callShow(ctx, "show interface transceiver details")
 	if err != nil {
 		return nil, err
 	}
@@ -421,19 +612,18 @@ func (cli *Client) GetTransceivers() ([]*Transceiver, error) {
 
 // Configure execute a batch of configuration commands
 func (cli *Client) Configure(cmds []string) ([]JSONRPCResponse, error) {
+	return cli.ConfigureContext(context.Background(), cmds)
+}
+
+// ConfigureContext is like Configure, but the request is bound to the
+// provided context.
+func (cli *Client) ConfigureContext(ctx context.Context, cmds []string) ([]JSONRPCResponse, error) {
 	if len(cmds) == 0 {
 		return nil, fmt.Errorf("empty input")
 	}
 
-	url := fmt.Sprintf("%s://%s:%d/ins", cli.protocol, cli.host, cli.port)
-
-	req := NewJSONRPCRequest(cmds)
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := callAPI("jsonrpc", url, payload, cli.username, cli.password, cli.secure)
+	// Configuration commands are not idempotent, hence never retried.
+	resp, err := cli.callAPIOnce(ctx, &Request{JSONRPC: NewJSONRPCRequest(cmds)})
 	if err != nil {
 		return nil, err
 	}
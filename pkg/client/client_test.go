@@ -16,6 +16,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -264,3 +265,51 @@ func TestClient(t *testing.T) {
 
 	t.Logf("client: took %s", time.Since(start))
 }
+
+func TestClientContext(t *testing.T) {
+	done := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ins", func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-done:
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	defer close(done)
+
+	cli := newTestClient(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := cli.GetSystemInfoContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("client: expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("client: request was not aborted on deadline, took %s", time.Since(start))
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := cli.ConfigureContext(ctx, []string{"vlan 1-2"}); err != context.Canceled {
+		t.Fatalf("client: expected %v, got %v", context.Canceled, err)
+	}
+}
+
+// newTestClient returns a Client pointed at a test server URL.
+func newTestClient(url string) *Client {
+	srv := strings.Split(url, ":")
+	port, _ := strconv.Atoi(srv[2])
+	cli := NewClient()
+	cli.SetHost("127.0.0.1")
+	cli.SetPort(port)
+	cli.SetProtocol(srv[0])
+	cli.SetUsername("admin")
+	cli.SetPassword("cisco")
+	return cli
+}
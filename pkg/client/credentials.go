@@ -0,0 +1,166 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Credentials are the username and password of basic authentication.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// CredentialProvider provides the credentials for the API calls to a host.
+// The client asks for the credentials each time it authenticates, so that
+// a provider may rotate them mid-run.
+type CredentialProvider interface {
+	Credentials(ctx context.Context, host string) (*Credentials, error)
+}
+
+// CredentialProviderFunc is a CredentialProvider calling the function.
+type CredentialProviderFunc func(ctx context.Context, host string) (*Credentials, error)
+
+// Credentials implements CredentialProvider.
+func (f CredentialProviderFunc) Credentials(ctx context.Context, host string) (*Credentials, error) {
+	return f(ctx, host)
+}
+
+// StaticCredentials returns a CredentialProvider with fixed credentials.
+func StaticCredentials(username, password string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context, string) (*Credentials, error) {
+		return &Credentials{Username: username, Password: password}, nil
+	})
+}
+
+// EnvCredentials returns a CredentialProvider reading the credentials from
+// the provided environment variables, e.g. "NXAPI_USERNAME" and
+// "NXAPI_PASSWORD".
+func EnvCredentials(usernameVar, passwordVar string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context, string) (*Credentials, error) {
+		creds := &Credentials{Username: os.Getenv(usernameVar), Password: os.Getenv(passwordVar)}
+		if creds.Username == "" || creds.Password == "" {
+			return nil, fmt.Errorf("credentials not found in %s and %s environment variables", usernameVar, passwordVar)
+		}
+		return creds, nil
+	})
+}
+
+// FileCredentials returns a CredentialProvider reading the credentials from
+// a secrets file, with the username on the first line and the password on
+// the second one. The file is read on each authentication.
+func FileCredentials(path string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context, string) (*Credentials, error) {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.Split(strings.Replace(string(b), "\r\n", "\n", -1), "\n")
+		if len(lines) < 2 || lines[0] == "" || lines[1] == "" {
+			return nil, fmt.Errorf("credentials not found in %s", path)
+		}
+		return &Credentials{Username: lines[0], Password: lines[1]}, nil
+	})
+}
+
+// NetrcCredentials returns a CredentialProvider reading the credentials
+// from a netrc file, e.g. ~/.netrc. The "machine" entry matching the host
+// is used, or the "default" entry when there is none.
+func NetrcCredentials(path string) CredentialProvider {
+	return CredentialProviderFunc(func(ctx context.Context, host string) (*Credentials, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		creds, err := parseNetrc(bufio.NewScanner(f), host)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+		return creds, nil
+	})
+}
+
+func parseNetrc(scanner *bufio.Scanner, host string) (*Credentials, error) {
+	scanner.Split(bufio.ScanWords)
+	var machine, def *Credentials
+	var current *Credentials
+	for scanner.Scan() {
+		switch scanner.Text() {
+		case "machine":
+			current = nil
+			if scanner.Scan() && scanner.Text() == host && machine == nil {
+				machine = &Credentials{}
+				current = machine
+			}
+		case "default":
+			current = nil
+			if def == nil {
+				def = &Credentials{}
+				current = def
+			}
+		case "login":
+			if scanner.Scan() && current != nil {
+				current.Username = scanner.Text()
+			}
+		case "password":
+			if scanner.Scan() && current != nil {
+				current.Password = scanner.Text()
+			}
+		case "account":
+			scanner.Scan()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if machine != nil {
+		return machine, nil
+	}
+	if def != nil {
+		return def, nil
+	}
+	return nil, fmt.Errorf("no credentials for %s", host)
+}
+
+// SetCredentialProvider instructs the client to get the credentials for
+// the API calls from the provider, instead of the username and password
+// set with SetUsername and SetPassword.
+func (cli *Client) SetCredentialProvider(p CredentialProvider) error {
+	if p == nil {
+		return fmt.Errorf("empty credential provider")
+	}
+	cli.credentialProvider = p
+	cli.ClearSession()
+	return nil
+}
+
+// credentials returns the credentials for the next authentication.
+func (cli *Client) credentials(ctx context.Context) (*Credentials, error) {
+	if cli.credentialProvider == nil {
+		return &Credentials{Username: cli.username, Password: cli.password}, nil
+	}
+	creds, err := cli.credentialProvider.Credentials(ctx, cli.host)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting credentials: %w", err)
+	}
+	return creds, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCredentialProviders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nxapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	netrc := filepath.Join(dir, "netrc")
+	ioutil.WriteFile(netrc, []byte("machine nysw01 login admin password cisco\n"+
+		"machine nysw02\n  login oper\n  account ops\n  password nexus\n"+
+		"default login guest password guest\n"), 0600)
+	secrets := filepath.Join(dir, "secrets")
+	ioutil.WriteFile(secrets, []byte("admin\ncisco123\n"), 0600)
+	os.Setenv("NXAPI_TEST_USERNAME", "envuser")
+	os.Setenv("NXAPI_TEST_PASSWORD", "envpass")
+	defer os.Unsetenv("NXAPI_TEST_USERNAME")
+	defer os.Unsetenv("NXAPI_TEST_PASSWORD")
+
+	testProviders := []struct {
+		provider  CredentialProvider
+		host      string
+		creds     Credentials
+		shouldErr bool
+	}{
+		{provider: StaticCredentials("admin", "cisco"), host: "nysw01", creds: Credentials{"admin", "cisco"}},
+		{provider: EnvCredentials("NXAPI_TEST_USERNAME", "NXAPI_TEST_PASSWORD"), host: "nysw01", creds: Credentials{"envuser", "envpass"}},
+		{provider: EnvCredentials("NXAPI_TEST_USERNAME", "NXAPI_TEST_MISSING"), host: "nysw01", shouldErr: true},
+		{provider: FileCredentials(secrets), host: "nysw01", creds: Credentials{"admin", "cisco123"}},
+		{provider: FileCredentials(filepath.Join(dir, "missing")), host: "nysw01", shouldErr: true},
+		{provider: NetrcCredentials(netrc), host: "nysw01", creds: Credentials{"admin", "cisco"}},
+		{provider: NetrcCredentials(netrc), host: "nysw02", creds: Credentials{"oper", "nexus"}},
+		{provider: NetrcCredentials(netrc), host: "nysw03", creds: Credentials{"guest", "guest"}},
+	}
+	for i, test := range testProviders {
+		creds, err := test.provider.Credentials(context.Background(), test.host)
+		if err != nil {
+			if !test.shouldErr {
+				t.Errorf("FAIL: Test %d: expected to pass, but failed: %s", i, err)
+			}
+			continue
+		}
+		if test.shouldErr {
+			t.Errorf("FAIL: Test %d: expected to fail, but passed", i)
+			continue
+		}
+		if *creds != test.creds {
+			t.Errorf("FAIL: Test %d: expected %v, got %v", i, test.creds, *creds)
+		}
+	}
+}
+
+func TestClientCredentialRotation(t *testing.T) {
+	password := "cisco"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		if user, pass, ok := req.BasicAuth(); !ok || user != "admin" || pass != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("<html><head><title>401 Authorization Required</title></head></html>"))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`))
+	}))
+	defer server.Close()
+
+	current := "cisco"
+	cli := newTestClient(server.URL)
+	cli.SetSessionReuse(false)
+	cli.SetCredentialProvider(CredentialProviderFunc(func(ctx context.Context, host string) (*Credentials, error) {
+		return &Credentials{Username: "admin", Password: current}, nil
+	}))
+	if _, err := cli.GetGeneric("show clock"); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	password, current = "rotated", "rotated"
+	if _, err := cli.GetGeneric("show clock"); err != nil {
+		t.Fatalf("client: rotated password not used: %s", err)
+	}
+}
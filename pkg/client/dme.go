@@ -0,0 +1,371 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DMECookieName is the name of the cookie carrying the token of the NX-API
+// REST API.
+const DMECookieName = "APIC-cookie"
+
+// DME is a client of the NX-API REST API, which exposes the DME object
+// model of NX-OS under /api. It shares the host, credentials and transport
+// of the Client it belongs to.
+type DME struct {
+	cli *Client
+
+	mu           sync.Mutex
+	token        string
+	tokenRefresh time.Time
+
+	// SubscriptionRefresh is the interval at which subscriptions are
+	// refreshed, see Subscribe.
+	SubscriptionRefresh time.Duration
+}
+
+// DME returns the NX-API REST client of the client.
+func (cli *Client) DME() *DME {
+	cli.dmeOnce.Do(func() {
+		cli.dme = &DME{cli: cli, SubscriptionRefresh: defaultSubscriptionRefresh}
+	})
+	return cli.dme
+}
+
+// ManagedObject is an object of the DME object model, e.g. an l1PhysIf
+// with its attributes and children.
+type ManagedObject struct {
+	Class      string
+	Attributes map[string]string
+	Children   []*ManagedObject
+}
+
+type managedObjectBody struct {
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Children   []*ManagedObject  `json:"children,omitempty"`
+}
+
+// UnmarshalJSON decodes the object from its JSON form, i.e. an object
+// keyed by the class of the managed object.
+func (mo *ManagedObject) UnmarshalJSON(b []byte) error {
+	var m map[string]*managedObjectBody
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	if len(m) != 1 {
+		return fmt.Errorf("expected a single class in managed object, got %d", len(m))
+	}
+	for class, body := range m {
+		mo.Class = class
+		if body != nil {
+			mo.Attributes = body.Attributes
+			mo.Children = body.Children
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the object in its JSON form.
+func (mo *ManagedObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]*managedObjectBody{
+		mo.Class: {Attributes: mo.Attributes, Children: mo.Children},
+	})
+}
+
+// Decode decodes the attributes of the object into target, e.g.
+// *L1PhysIf.
+func (mo *ManagedObject) Decode(target interface{}) error {
+	b, err := json.Marshal(mo.Attributes)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, target)
+}
+
+// DMEResponse is the payload of NX-API REST responses.
+type DMEResponse struct {
+	TotalCount     string           `json:"totalCount"`
+	SubscriptionID string           `json:"subscriptionId,omitempty"`
+	Imdata         []*ManagedObject `json:"imdata"`
+}
+
+// DMEError is an error returned by the NX-API REST API.
+type DMEError struct {
+	StatusCode int
+	Code       string
+	Text       string
+}
+
+func (e *DMEError) Error() string {
+	if e.Text != "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, e.Text)
+	}
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is reports whether the error matches one of the errors of this package.
+func (e *DMEError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrInvalidRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrServerInternal:
+		return e.StatusCode == http.StatusInternalServerError
+	}
+	return false
+}
+
+// DMEQuery holds the options of NX-API REST queries.
+type DMEQuery struct {
+	// QueryTarget is the scope of the query: "self", "children" or
+	// "subtree".
+	QueryTarget string
+	// TargetSubtreeClass limits the returned objects to the classes, e.g.
+	// "l1PhysIf".
+	TargetSubtreeClass string
+	// QueryTargetFilter filters the returned objects, e.g.
+	// `eq(l1PhysIf.adminSt,"down")`.
+	QueryTargetFilter string
+	// RspSubtree includes the children of the returned objects: "no",
+	// "children" or "full".
+	RspSubtree string
+	// RspSubtreeClass limits the returned children to the classes.
+	RspSubtreeClass string
+	// RspSubtreeInclude adds related objects, e.g. "faults".
+	RspSubtreeInclude string
+}
+
+func (q *DMEQuery) values() url.Values {
+	v := url.Values{}
+	if q == nil {
+		return v
+	}
+	for k, s := range map[string]string{
+		"query-target":         q.QueryTarget,
+		"target-subtree-class": q.TargetSubtreeClass,
+		"query-target-filter":  q.QueryTargetFilter,
+		"rsp-subtree":          q.RspSubtree,
+		"rsp-subtree-class":    q.RspSubtreeClass,
+		"rsp-subtree-include":  q.RspSubtreeInclude,
+	} {
+		if s != "" {
+			v.Set(k, s)
+		}
+	}
+	return v
+}
+
+// Login authenticates with the credentials of the client and stores the
+// token for the subsequent requests.
+func (d *DME) Login(ctx context.Context) error {
+	creds, err := d.cli.credentials(ctx)
+	if err != nil {
+		return err
+	}
+	login := &ManagedObject{
+		Class:      "aaaUser",
+		Attributes: map[string]string{"name": creds.Username, "pwd": creds.Password},
+	}
+	payload, err := json.Marshal(login)
+	if err != nil {
+		return err
+	}
+	resp, err := d.send(ctx, "POST", "/api/aaaLogin.json", nil, payload, "")
+	if err != nil {
+		return err
+	}
+	return d.setToken(resp, "aaaLogin")
+}
+
+// Refresh extends the validity of the token. The token is refreshed
+// automatically before it expires, as long as the DME is in use.
+func (d *DME) Refresh(ctx context.Context) error {
+	d.mu.Lock()
+	token := d.token
+	d.mu.Unlock()
+	if token == "" {
+		return d.Login(ctx)
+	}
+	resp, err := d.send(ctx, "GET", "/api/aaaRefresh.json", nil, nil, token)
+	if err != nil {
+		return err
+	}
+	return d.setToken(resp, "aaaRefresh")
+}
+
+// setToken stores the token of an aaaLogin or aaaRefresh response.
+func (d *DME) setToken(resp *DMEResponse, class string) error {
+	for _, mo := range resp.Imdata {
+		if mo.Class != class || mo.Attributes["token"] == "" {
+			continue
+		}
+		timeout, _ := strconv.Atoi(mo.Attributes["refreshTimeoutSeconds"])
+		if timeout <= 0 {
+			timeout = 600
+		}
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.token = mo.Attributes["token"]
+		// Refresh the token half-way through its validity.
+		d.tokenRefresh = time.Now().Add(time.Duration(timeout) * time.Second / 2)
+		return nil
+	}
+	return fmt.Errorf("no token in %s response", class)
+}
+
+// GetMO returns the managed object with the distinguished name, e.g.
+// "sys/intf/phys-[eth1/1]", or its children or subtree, depending on the
+// query.
+func (d *DME) GetMO(ctx context.Context, dn string, q *DMEQuery) ([]*ManagedObject, error) {
+	resp, err := d.do(ctx, "GET", "/api/mo/"+dn+".json", q.values(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Imdata, nil
+}
+
+// GetClass returns the managed objects of the class, e.g. "l1PhysIf".
+func (d *DME) GetClass(ctx context.Context, class string, q *DMEQuery) ([]*ManagedObject, error) {
+	resp, err := d.do(ctx, "GET", "/api/class/"+class+".json", q.values(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Imdata, nil
+}
+
+// PostMO creates or modifies the managed object at the distinguished name.
+func (d *DME) PostMO(ctx context.Context, dn string, mo *ManagedObject) error {
+	payload, err := json.Marshal(mo)
+	if err != nil {
+		return err
+	}
+	_, err = d.do(ctx, "POST", "/api/mo/"+dn+".json", nil, payload)
+	return err
+}
+
+// DeleteMO deletes the managed object at the distinguished name.
+func (d *DME) DeleteMO(ctx context.Context, dn string) error {
+	_, err := d.do(ctx, "DELETE", "/api/mo/"+dn+".json", nil, nil)
+	return err
+}
+
+// do sends an authenticated request, logging in first when needed and once
+// more when the device rejects the token.
+func (d *DME) do(ctx context.Context, method, path string, query url.Values, payload []byte) (*DMEResponse, error) {
+	token, err := d.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.send(ctx, method, path, query, payload, token)
+	if errors.Is(err, ErrUnauthorized) {
+		if err := d.Login(ctx); err != nil {
+			return nil, err
+		}
+		token, _ = d.validToken(ctx)
+		resp, err = d.send(ctx, method, path, query, payload, token)
+	}
+	return resp, err
+}
+
+// validToken returns the token, logging in or refreshing it when needed.
+func (d *DME) validToken(ctx context.Context) (string, error) {
+	d.mu.Lock()
+	token, refresh := d.token, d.tokenRefresh
+	d.mu.Unlock()
+	switch {
+	case token == "":
+		if err := d.Login(ctx); err != nil {
+			return "", err
+		}
+	case time.Now().After(refresh):
+		if err := d.Refresh(ctx); err != nil {
+			if err := d.Login(ctx); err != nil {
+				return "", err
+			}
+		}
+	default:
+		return token, nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.token, nil
+}
+
+// send performs a single request to the REST API.
+func (d *DME) send(ctx context.Context, method, path string, query url.Values, payload []byte, token string) (*DMEResponse, error) {
+	cli := d.cli
+	release, err := cli.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	u := fmt.Sprintf("%s://%s:%d%s", cli.protocol, cli.host, cli.port, path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: DMECookieName, Value: token})
+	}
+	res, err := cli.getHTTPClient().Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DMEResponse{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, resp); err != nil {
+			if res.StatusCode >= 400 {
+				return nil, &DMEError{StatusCode: res.StatusCode, Text: strings.TrimSpace(string(body))}
+			}
+			return nil, fmt.Errorf("parsing error: %s, server response: %s", err, string(body[:]))
+		}
+	}
+	for _, mo := range resp.Imdata {
+		if mo.Class == "error" {
+			return nil, &DMEError{StatusCode: res.StatusCode, Code: mo.Attributes["code"], Text: mo.Attributes["text"]}
+		}
+	}
+	if res.StatusCode >= 400 {
+		return nil, &DMEError{StatusCode: res.StatusCode}
+	}
+	return resp, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+)
+
+// L1PhysIf is a physical interface, i.e. an l1PhysIf object of the DME
+// object model.
+type L1PhysIf struct {
+	DN           string `json:"dn"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Descr        string `json:"descr"`
+	AdminSt      string `json:"adminSt"`
+	Mode         string `json:"mode"`
+	Layer        string `json:"layer"`
+	Speed        string `json:"speed"`
+	Duplex       string `json:"duplex"`
+	AutoNeg      string `json:"autoNeg"`
+	Mtu          string `json:"mtu"`
+	Medium       string `json:"medium"`
+	PortT        string `json:"portT"`
+	Usage        string `json:"usage"`
+	AccessVlan   string `json:"accessVlan"`
+	NativeVlan   string `json:"nativeVlan"`
+	TrunkVlans   string `json:"trunkVlans"`
+	ModTs        string `json:"modTs"`
+	Status       string `json:"status"`
+	SnmpTrapSt   string `json:"snmpTrapSt"`
+	LinkDebounce string `json:"linkDebounce"`
+}
+
+// BgpPeerEntry is the operational state of a BGP session, i.e. a
+// bgpPeerEntry object of the DME object model.
+type BgpPeerEntry struct {
+	DN           string `json:"dn"`
+	Addr         string `json:"addr"`
+	OperSt       string `json:"operSt"`
+	PrevSt       string `json:"prevSt"`
+	Type         string `json:"type"`
+	RtrID        string `json:"rtrId"`
+	ConnAttempts string `json:"connAttempts"`
+	ConnEst      string `json:"connEst"`
+	ConnDrop     string `json:"connDrop"`
+	HoldIntvl    string `json:"holdIntvl"`
+	KaIntvl      string `json:"kaIntvl"`
+	LastFlapTs   string `json:"lastFlapTs"`
+	Flags        string `json:"flags"`
+	ModTs        string `json:"modTs"`
+}
+
+// GetL1PhysIfs returns the physical interfaces of the device.
+func (d *DME) GetL1PhysIfs(ctx context.Context) ([]*L1PhysIf, error) {
+	mos, err := d.GetClass(ctx, "l1PhysIf", nil)
+	if err != nil {
+		return nil, err
+	}
+	var ifaces []*L1PhysIf
+	for _, mo := range mos {
+		iface := &L1PhysIf{}
+		if err := mo.Decode(iface); err != nil {
+			return nil, err
+		}
+		ifaces = append(ifaces, iface)
+	}
+	return ifaces, nil
+}
+
+// GetBgpPeerEntries returns the BGP sessions of the device, in all VRFs.
+func (d *DME) GetBgpPeerEntries(ctx context.Context) ([]*BgpPeerEntry, error) {
+	mos, err := d.GetClass(ctx, "bgpPeerEntry", nil)
+	if err != nil {
+		return nil, err
+	}
+	var peers []*BgpPeerEntry
+	for _, mo := range mos {
+		peer := &BgpPeerEntry{}
+		if err := mo.Decode(peer); err != nil {
+			return nil, err
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// NX-OS drops subscriptions not refreshed within 60 seconds.
+	defaultSubscriptionRefresh = 30 * time.Second
+	minReconnectWait           = time.Second
+	maxReconnectWait           = 30 * time.Second
+)
+
+// DMEEvent is a change of a managed object, delivered by a subscription.
+type DMEEvent struct {
+	// Query is the subscribed query the event matches, e.g.
+	// "class/l1PhysIf".
+	Query string
+	// Status is the change of the object: "created", "modified" or
+	// "deleted".
+	Status string
+	// Object holds the dn and the changed attributes of the object.
+	Object *ManagedObject
+}
+
+// Decode decodes the attributes of the changed object into target, e.g.
+// *L1PhysIf.
+func (e *DMEEvent) Decode(target interface{}) error {
+	return e.Object.Decode(target)
+}
+
+// DMESubscription delivers the changes of the managed objects matching its
+// queries. See DME.Subscribe.
+type DMESubscription struct {
+	dme     *DME
+	queries []string
+	events  chan *DMEEvent
+	errs    chan error
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+	ids  map[string]string
+}
+
+type dmeEventMessage struct {
+	SubscriptionID []string         `json:"subscriptionId"`
+	Imdata         []*ManagedObject `json:"imdata"`
+}
+
+// Subscribe opens the websocket of the NX-API REST API and subscribes to
+// the queries, e.g. "class/l1PhysIf" or "mo/sys/bgp/inst/dom-default".
+// The changes of the matching objects are delivered on the Events channel
+// of the subscription until the context is done or the subscription is
+// closed. The subscription refreshes the queries before they expire, and
+// reconnects and subscribes again when the websocket fails.
+func (d *DME) Subscribe(ctx context.Context, queries ...string) (*DMESubscription, error) {
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("empty input")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &DMESubscription{
+		dme:     d,
+		queries: queries,
+		events:  make(chan *DMEEvent, 64),
+		errs:    make(chan error, 1),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	// The first connection is established synchronously, so that bad
+	// credentials or queries are reported right away.
+	if err := s.connect(ctx); err != nil {
+		cancel()
+		return nil, err
+	}
+	go s.run(ctx)
+	return s, nil
+}
+
+// Events returns the channel of the changes. It is closed when the
+// subscription ends.
+func (s *DMESubscription) Events() <-chan *DMEEvent {
+	return s.events
+}
+
+// Errors returns the channel of the connection failures the subscription
+// recovered from by reconnecting. Reading it is optional.
+func (s *DMESubscription) Errors() <-chan error {
+	return s.errs
+}
+
+// Close ends the subscription.
+func (s *DMESubscription) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// connect opens the websocket and subscribes to the queries.
+func (s *DMESubscription) connect(ctx context.Context) error {
+	d := s.dme
+	cli := d.cli
+	token, err := d.validToken(ctx)
+	if err != nil {
+		return err
+	}
+	scheme := "ws"
+	if cli.protocol == "https" {
+		scheme = "wss"
+	}
+	dialer := &websocket.Dialer{
+		Proxy:            cli.proxy,
+		NetDialContext:   cli.dialContext,
+		TLSClientConfig:  cli.tlsConfig(),
+		HandshakeTimeout: cli.tlsHandshakeTimeout,
+	}
+	u := fmt.Sprintf("%s://%s:%d/socket%s", scheme, cli.host, cli.port, url.PathEscape(token))
+	conn, _, err := dialer.DialContext(ctx, u, nil)
+	if err != nil {
+		return fmt.Errorf("websocket: %w", err)
+	}
+	ids := make(map[string]string)
+	for _, q := range s.queries {
+		resp, err := d.do(ctx, "GET", "/api/"+strings.TrimPrefix(q, "/")+".json", url.Values{"subscription": {"yes"}}, nil)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		if resp.SubscriptionID == "" {
+			conn.Close()
+			return fmt.Errorf("no subscription id for %s", q)
+		}
+		ids[resp.SubscriptionID] = q
+	}
+	s.mu.Lock()
+	s.conn, s.ids = conn, ids
+	s.mu.Unlock()
+	return nil
+}
+
+// run delivers the events, refreshes the subscriptions and reconnects
+// until the context is done.
+func (s *DMESubscription) run(ctx context.Context) {
+	defer close(s.done)
+	defer close(s.events)
+	wait := minReconnectWait
+	for {
+		err := s.serve(ctx)
+		s.mu.Lock()
+		s.conn.Close()
+		s.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case s.errs <- err:
+		default:
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			if err := s.connect(ctx); err == nil {
+				wait = minReconnectWait
+				break
+			}
+			if wait *= 2; wait > maxReconnectWait {
+				wait = maxReconnectWait
+			}
+		}
+	}
+}
+
+// serve reads the websocket until it fails, while refreshing the
+// subscriptions.
+func (s *DMESubscription) serve(ctx context.Context) error {
+	s.mu.Lock()
+	conn, ids := s.conn, s.ids
+	s.mu.Unlock()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+	go s.refresh(ctx, ids, stop)
+
+	for {
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("websocket: %w", err)
+		}
+		msg := &dmeEventMessage{}
+		if err := json.Unmarshal(b, msg); err != nil {
+			continue
+		}
+		var query string
+		for _, id := range msg.SubscriptionID {
+			if q, found := ids[id]; found {
+				query = q
+				break
+			}
+		}
+		for _, mo := range msg.Imdata {
+			e := &DMEEvent{Query: query, Status: mo.Attributes["status"], Object: mo}
+			select {
+			case s.events <- e:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// refresh keeps the subscriptions alive until stop is closed.
+func (s *DMESubscription) refresh(ctx context.Context, ids map[string]string, stop chan struct{}) {
+	ticker := time.NewTicker(s.dme.SubscriptionRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for id := range ids {
+			// A failed refresh is not fatal: when the device is gone, the
+			// read error of the websocket triggers the reconnection.
+			s.dme.do(ctx, "GET", "/api/subscriptionRefresh.json", url.Values{"id": {id}}, nil)
+		}
+	}
+}
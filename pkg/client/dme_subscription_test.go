@@ -0,0 +1,120 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientDMESubscribe(t *testing.T) {
+	var mu sync.Mutex
+	var conns, subscriptions, refreshes int
+	var conn *websocket.Conn
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case req.URL.Path == "/api/aaaLogin.json":
+			w.Write([]byte(`{"imdata":[{"aaaLogin":{"attributes":{"token":"token","refreshTimeoutSeconds":"600"}}}]}`))
+		case req.URL.Path == "/sockettoken":
+			c, err := upgrader.Upgrade(w, req, nil)
+			if err != nil {
+				t.Errorf("server: %s", err)
+				return
+			}
+			conns++
+			conn = c
+		case req.URL.Path == "/api/class/l1PhysIf.json" && req.URL.Query().Get("subscription") == "yes":
+			subscriptions++
+			id := fmt.Sprintf("sub-%d", subscriptions)
+			fmt.Fprintf(w, `{"subscriptionId":"%s","imdata":[]}`, id)
+			// The device notifies of a change, then drops the first
+			// connection.
+			c, n := conn, subscriptions
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"subscriptionId":["%s"],"imdata":[{"l1PhysIf":`+
+					`{"attributes":{"dn":"sys/intf/phys-[eth1/%d]","id":"eth1/%d","adminSt":"down","status":"modified"}}}]}`, id, n, n)))
+				if n == 1 {
+					time.Sleep(50 * time.Millisecond)
+					c.Close()
+				}
+			}()
+		case req.URL.Path == "/api/subscriptionRefresh.json":
+			refreshes++
+			w.Write([]byte(`{"imdata":[]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"imdata":[{"error":{"attributes":{"code":"400","text":"Request failed, unresolved class"}}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	dme := newTestClient(server.URL).DME()
+	dme.SubscriptionRefresh = 20 * time.Millisecond
+	if _, err := dme.Subscribe(context.Background(), "class/fooBar"); err == nil {
+		t.Fatalf("client: expected subscription error for unresolved class")
+	}
+	sub, err := dme.Subscribe(context.Background(), "class/l1PhysIf")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case e := <-sub.Events():
+			var iface L1PhysIf
+			if err := e.Decode(&iface); err != nil {
+				t.Fatalf("client: %s", err)
+			}
+			if e.Query != "class/l1PhysIf" || e.Status != "modified" || iface.ID != fmt.Sprintf("eth1/%d", i) || iface.AdminSt != "down" {
+				t.Fatalf("client: unexpected event %d: %+v, %+v", i, e, iface)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("client: no event %d", i)
+		}
+	}
+	select {
+	case err := <-sub.Errors():
+		if !strings.Contains(err.Error(), "websocket") {
+			t.Fatalf("client: unexpected error: %s", err)
+		}
+	default:
+		t.Fatalf("client: connection failure not reported")
+	}
+	time.Sleep(50 * time.Millisecond)
+	sub.Close()
+	if _, ok := <-sub.Events(); ok {
+		t.Fatalf("client: events channel not closed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The failed subscription to the unresolved class opened a connection.
+	if conns != 3 || subscriptions != 2 || refreshes == 0 {
+		t.Fatalf("client: expected 3 connections, 2 subscriptions and refreshes, got %d, %d, %d", conns, subscriptions, refreshes)
+	}
+}
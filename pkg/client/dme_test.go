@@ -0,0 +1,157 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClientDME(t *testing.T) {
+	dataDir := "testdata/synthetic"
+	var logins, refreshes int
+	token := ""
+	var posted *ManagedObject
+	var deleted, query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		if req.URL.Path == "/api/aaaLogin.json" {
+			var login *ManagedObject
+			if err := json.Unmarshal(body, &login); err != nil || login.Class != "aaaUser" ||
+				login.Attributes["name"] != "admin" || login.Attributes["pwd"] != "cisco" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"imdata":[{"error":{"attributes":{"code":"401","text":"Username or password is incorrect"}}}]}`))
+				return
+			}
+			logins++
+			token = fmt.Sprintf("token-%d", logins)
+			fmt.Fprintf(w, `{"imdata":[{"aaaLogin":{"attributes":{"token":"%s","refreshTimeoutSeconds":"600"}}}]}`, token)
+			return
+		}
+		if c, err := req.Cookie(DMECookieName); err != nil || c.Value != token {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"imdata":[{"error":{"attributes":{"code":"403","text":"Token was invalid (Error: Token timeout)"}}}]}`))
+			return
+		}
+		switch {
+		case req.URL.Path == "/api/aaaRefresh.json":
+			refreshes++
+			token += "-refreshed"
+			fmt.Fprintf(w, `{"imdata":[{"aaaRefresh":{"attributes":{"token":"%s","refreshTimeoutSeconds":"600"}}}]}`, token)
+		case req.URL.Path == "/api/class/l1PhysIf.json" || req.URL.Path == "/api/class/bgpPeerEntry.json":
+			query = req.URL.RawQuery
+			fc, err := ioutil.ReadFile(fmt.Sprintf("%s/resp.dme.class.%s", dataDir, req.URL.Path[len("/api/class/"):]))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Write(fc)
+		case req.URL.Path == "/api/mo/sys/intf/phys-[eth1/1].json" && req.Method == "GET":
+			query = req.URL.RawQuery
+			w.Write([]byte(`{"totalCount":"1","imdata":[{"l1PhysIf":{"attributes":{"id":"eth1/1","adminSt":"up"},` +
+				`"children":[{"ethpmPhysIf":{"attributes":{"operSt":"up"}}}]}}]}`))
+		case req.URL.Path == "/api/mo/sys/intf/phys-[eth1/2].json" && req.Method == "POST":
+			json.Unmarshal(body, &posted)
+			w.Write([]byte(`{"imdata":[]}`))
+		case req.URL.Path == "/api/mo/sys/bd/bd-[vlan-10].json" && req.Method == "DELETE":
+			deleted = req.URL.Path
+			w.Write([]byte(`{"imdata":[]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"imdata":[{"error":{"attributes":{"code":"400","text":"Request failed, unresolved class"}}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	dme := newTestClient(server.URL).DME()
+
+	ifaces, err := dme.GetL1PhysIfs(ctx)
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(ifaces) != 2 || ifaces[0].ID != "eth1/1" || ifaces[0].Mtu != "9216" || ifaces[1].AdminSt != "down" {
+		t.Fatalf("client: unexpected interfaces: %+v", ifaces)
+	}
+	peers, err := dme.GetBgpPeerEntries(ctx)
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(peers) != 1 || peers[0].Addr != "10.1.1.2" || peers[0].OperSt != "established" {
+		t.Fatalf("client: unexpected bgp peers: %+v", peers)
+	}
+	if logins != 1 {
+		t.Fatalf("client: expected a single login, got %d", logins)
+	}
+
+	mos, err := dme.GetMO(ctx, "sys/intf/phys-[eth1/1]", &DMEQuery{RspSubtree: "children", RspSubtreeClass: "ethpmPhysIf"})
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	expMO := &ManagedObject{
+		Class:      "l1PhysIf",
+		Attributes: map[string]string{"id": "eth1/1", "adminSt": "up"},
+		Children:   []*ManagedObject{{Class: "ethpmPhysIf", Attributes: map[string]string{"operSt": "up"}}},
+	}
+	if len(mos) != 1 || !reflect.DeepEqual(mos[0], expMO) {
+		t.Fatalf("client: unexpected managed objects: %+v", mos)
+	}
+	if query != "rsp-subtree=children&rsp-subtree-class=ethpmPhysIf" {
+		t.Fatalf("client: unexpected query: %s", query)
+	}
+
+	mo := &ManagedObject{Class: "l1PhysIf", Attributes: map[string]string{"adminSt": "up", "descr": "server"}}
+	if err := dme.PostMO(ctx, "sys/intf/phys-[eth1/2]", mo); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if !reflect.DeepEqual(posted, mo) {
+		t.Fatalf("client: unexpected posted object: %+v", posted)
+	}
+	if err := dme.DeleteMO(ctx, "sys/bd/bd-[vlan-10]"); err != nil || deleted == "" {
+		t.Fatalf("client: delete failed: %v", err)
+	}
+
+	// The token is refreshed, and the client logs in again when the
+	// device rejects it.
+	if err := dme.Refresh(ctx); err != nil || refreshes != 1 {
+		t.Fatalf("client: refresh failed: %v", err)
+	}
+	token = "expired"
+	if _, err := dme.GetClass(ctx, "l1PhysIf", nil); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if logins != 2 {
+		t.Fatalf("client: expected a second login, got %d", logins)
+	}
+
+	_, err = dme.GetClass(ctx, "fooBar", nil)
+	var dmeErr *DMEError
+	if !errors.As(err, &dmeErr) || dmeErr.Code != "400" || !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("client: expected invalid request error, got: %v", err)
+	}
+
+	bad := newTestClient(server.URL)
+	bad.SetPassword("wrong")
+	if _, err := bad.DME().GetClass(ctx, "l1PhysIf", nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("client: expected unauthorized error, got: %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// The errors below classify the failures of the API calls. Use errors.Is to
+// test for them, e.g. errors.Is(err, ErrUnauthorized). Errors of the
+// underlying transport, e.g. a refused connection or a timeout when the
+// device is down, are returned as is.
+var (
+	// ErrUnauthorized is returned when the device rejects the credentials.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotAllowed is returned when the device does not allow the request,
+	// e.g. NX-API is reached with a wrong HTTP method.
+	ErrNotAllowed = errors.New("not allowed")
+	// ErrServerInternal is returned when NX-API fails to process the request.
+	ErrServerInternal = errors.New("server internal error")
+	// ErrInvalidRequest is returned when NX-API cannot parse the request.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrInvalidCommand is returned when the command has a syntax error.
+	ErrInvalidCommand = errors.New("invalid command")
+	// ErrCommandNotSupported is returned when the command has no structured
+	// output, or is not supported by the API.
+	ErrCommandNotSupported = errors.New("command not supported")
+)
+
+// HTTPError is returned when NX-API responds with an HTTP error, e.g. "401
+// Authorization Required".
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *HTTPError) Error() string {
+	if e.Status != "" {
+		return e.Status
+	}
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is reports whether the error matches one of the errors of this package.
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotAllowed:
+		return e.StatusCode == http.StatusMethodNotAllowed
+	case ErrServerInternal:
+		return e.StatusCode == http.StatusInternalServerError
+	}
+	return false
+}
+
+// RPCError is an error reported by NX-API for a command, either in a
+// JSON-RPC response or in the output of an NX-OS API (ins_api) response.
+type RPCError struct {
+	Code    int64                    `json:"code" xml:"code"`
+	Message string                   `json:"message" xml:"message"`
+	Data    JSONRPCResponseErrorData `json:"data" xml:"data"`
+}
+
+func (e *RPCError) Error() string {
+	if e.Data.Msg != "" {
+		return fmt.Sprintf("%d %s: %s", e.Code, e.Message, e.Data.Msg)
+	}
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// Is reports whether the error matches one of the errors of this package.
+func (e *RPCError) Is(target error) bool {
+	switch target {
+	case ErrInvalidRequest:
+		return e.Code == -32700 || e.Code == -32600 || e.Code == -32601
+	case ErrInvalidCommand:
+		return e.Code == -32602 || e.Code == 400
+	case ErrCommandNotSupported:
+		return e.Code == 501
+	case ErrServerInternal:
+		return e.Code == -32603 || e.Code == 500
+	}
+	return false
+}
+
+// newHTTPError returns *HTTPError when the response is an HTTP error rather
+// than an API response.
+func newHTTPError(res *http.Response, body []byte) error {
+	if len(body) < 500 {
+		switch {
+		case bytes.Contains(body, []byte("401 Authorization Required")):
+			return &HTTPError{StatusCode: http.StatusUnauthorized, Status: "401 Authorization Required", Body: body}
+		case bytes.Contains(body, []byte("405 Not Allowed")):
+			return &HTTPError{StatusCode: http.StatusMethodNotAllowed, Status: "405 Not Allowed", Body: body}
+		case bytes.Contains(body, []byte("Server internal error")):
+			return &HTTPError{StatusCode: http.StatusInternalServerError, Status: "500 Server Internal Error", Body: body}
+		}
+	}
+	if res.StatusCode < 400 {
+		return nil
+	}
+	// JSON-RPC errors may arrive with an HTTP error status, they are
+	// reported per command.
+	if b := bytes.TrimSpace(body); len(b) > 0 && (b[0] == '{' || b[0] == '[') {
+		return nil
+	}
+	if bytes.Contains(body, []byte("<ins_api>")) {
+		return nil
+	}
+	return &HTTPError{StatusCode: res.StatusCode, Status: res.Status, Body: body}
+}
+
+// newRPCError returns the first error found in a JSON-RPC response, or nil.
+func newRPCError(s []byte) error {
+	s = bytes.TrimSpace(s)
+	if len(s) == 0 {
+		return nil
+	}
+	var resps []JSONRPCResponse
+	if s[0] == '[' {
+		if err := json.Unmarshal(s, &resps); err != nil {
+			return nil
+		}
+	} else {
+		var resp JSONRPCResponse
+		if err := json.Unmarshal(s, &resp); err != nil {
+			return nil
+		}
+		resps = append(resps, resp)
+	}
+	for i := range resps {
+		if err := resps[i].Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type insAPIErrorResponse struct {
+	InsAPI struct {
+		Outputs struct {
+			Output json.RawMessage `json:"output"`
+		} `json:"outputs"`
+	} `json:"ins_api"`
+}
+
+type insAPIErrorOutput struct {
+	Code     string `json:"code"`
+	Message  string `json:"msg"`
+	CliError string `json:"clierror"`
+}
+
+// newInsAPIError returns the first error found in the outputs of an NX-OS
+// API response, or nil.
+func newInsAPIError(s []byte) error {
+	resp := &insAPIErrorResponse{}
+	if err := json.Unmarshal(s, resp); err != nil {
+		return nil
+	}
+	var outputs []insAPIErrorOutput
+	if err := json.Unmarshal(resp.InsAPI.Outputs.Output, &outputs); err != nil {
+		var output insAPIErrorOutput
+		if err := json.Unmarshal(resp.InsAPI.Outputs.Output, &output); err != nil {
+			return nil
+		}
+		outputs = append(outputs, output)
+	}
+	for _, output := range outputs {
+		if err := output.err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *insAPIErrorOutput) err() error {
+	if o.Code == "" || o.Code == "200" {
+		return nil
+	}
+	code, _ := strconv.ParseInt(o.Code, 10, 64)
+	return &RPCError{
+		Code:    code,
+		Message: o.Message,
+		Data:    JSONRPCResponseErrorData{Msg: o.CliError},
+	}
+}
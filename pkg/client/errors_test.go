@@ -0,0 +1,118 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientErrors(t *testing.T) {
+	testFailed := 0
+	outputDir := "../../assets/requests"
+
+	for i, test := range []struct {
+		input      string
+		statusCode int
+		exp        error
+		expHTTP    int
+		expRPC     int64
+	}{
+		{input: "error.401.unauthorized", statusCode: 401, exp: ErrUnauthorized, expHTTP: 401},
+		{input: "error.405.not.allowed", statusCode: 405, exp: ErrNotAllowed, expHTTP: 405},
+		{input: "error.400.bad.request", statusCode: 400, exp: ErrServerInternal, expHTTP: 500},
+		{input: "error.200.invalid.request", statusCode: 200, exp: ErrInvalidRequest, expRPC: -32600},
+	} {
+		fp := fmt.Sprintf("%s/resp.%s.txt", outputDir, test.input)
+		content, err := ioutil.ReadFile(fp)
+		if err != nil {
+			t.Logf("FAIL: Test %d: failed reading '%s', error: %v", i, fp, err)
+			testFailed++
+			continue
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(test.statusCode)
+			w.Write(content)
+		}))
+		cli := newTestClient(server.URL)
+		_, err = cli.GetSystemInfo()
+		server.Close()
+		if !errors.Is(err, test.exp) {
+			t.Logf("FAIL: Test %d: input '%s', expected error %v, got: %v", i, test.input, test.exp, err)
+			testFailed++
+			continue
+		}
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) != (test.expHTTP != 0) || (httpErr != nil && httpErr.StatusCode != test.expHTTP) {
+			t.Logf("FAIL: Test %d: input '%s', expected HTTP status %d, got: %#v", i, test.input, test.expHTTP, err)
+			testFailed++
+			continue
+		}
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) != (test.expRPC != 0) || (rpcErr != nil && rpcErr.Code != test.expRPC) {
+			t.Logf("FAIL: Test %d: input '%s', expected RPC code %d, got: %#v", i, test.input, test.expRPC, err)
+			testFailed++
+			continue
+		}
+		t.Logf("PASS: Test %d: input '%s', error: %v", i, test.input, err)
+	}
+	if testFailed > 0 {
+		t.Fatalf("Failed %d tests", testFailed)
+	}
+}
+
+func TestRPCErrorDecoding(t *testing.T) {
+	content, err := ioutil.ReadFile("../../assets/requests/resp.error.200.invalid.request.txt")
+	if err != nil {
+		t.Fatalf("failed reading input: %s", err)
+	}
+	err = newRPCError(content)
+	rpcErr, ok := err.(*RPCError)
+	if !ok {
+		t.Fatalf("expected *RPCError, got: %#v", err)
+	}
+	if rpcErr.Code != -32600 || rpcErr.Message != "Invalid request" ||
+		rpcErr.Data.Msg != "JSON-RPC version structure was not found in request" {
+		t.Fatalf("unexpected error fields: %#v", rpcErr)
+	}
+
+	err = newInsAPIError([]byte(`{"ins_api":{"outputs":{"output":[{"code":"200","msg":"Success","body":{}},` +
+		`{"code":"400","msg":"Input CLI command error","clierror":"% Invalid command at '^' marker.\n"}]}}}`))
+	if !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("expected %v, got: %v", ErrInvalidCommand, err)
+	}
+	if err := newInsAPIError([]byte(`{"ins_api":{"outputs":{"output":{"code":"200","msg":"Success","body":"ok"}}}}`)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestClientUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	cli := newTestClient(server.URL)
+	server.Close()
+	_, err := cli.GetSystemInfo()
+	if err == nil {
+		t.Fatalf("expected error for unreachable device")
+	}
+	var httpErr *HTTPError
+	var rpcErr *RPCError
+	if errors.As(err, &httpErr) || errors.As(err, &rpcErr) || errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected transport error, got: %#v", err)
+	}
+}
@@ -14,6 +14,13 @@
 
 package client
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
 type insAPIResponse struct {
 	Result insAPIResponseResult `json:"ins_api" xml:"ins_api"`
 }
@@ -34,3 +41,140 @@ type insAPIResponseResultOutputsOutput struct {
 	Message string `json:"msg" xml:"msg"`
 	Input   string `json:"input" xml:"input"`
 }
+
+// InsAPIOutput is the output of a command in an NX-OS API response.
+type InsAPIOutput struct {
+	Input    string          `json:"input" xml:"input"`
+	Code     string          `json:"code" xml:"code"`
+	Message  string          `json:"msg" xml:"msg"`
+	CliError string          `json:"clierror" xml:"clierror"`
+	Body     json.RawMessage `json:"body" xml:"body"`
+}
+
+// Err returns *RPCError when the command failed, or nil.
+func (o *InsAPIOutput) Err() error {
+	e := &insAPIErrorOutput{Code: o.Code, Message: o.Message, CliError: o.CliError}
+	return e.err()
+}
+
+// Text returns the body of cli_show_ascii, cli_conf and bash outputs.
+func (o *InsAPIOutput) Text() string {
+	var s string
+	if err := json.Unmarshal(o.Body, &s); err != nil {
+		return ""
+	}
+	return s
+}
+
+// Decode decodes the body of cli_show and cli_show_array outputs into
+// target, e.g. *ShowVpcResultBody.
+func (o *InsAPIOutput) Decode(target interface{}) error {
+	return decodeResult(o.Body, target)
+}
+
+// InsAPIOutputs are the outputs of an NX-OS API response. The API returns
+// a single output as an object, and multiple outputs as an array.
+type InsAPIOutputs []*InsAPIOutput
+
+// UnmarshalJSON decodes an output or an array of outputs.
+func (o *InsAPIOutputs) UnmarshalJSON(b []byte) error {
+	var arr []*InsAPIOutput
+	if err := json.Unmarshal(b, &arr); err == nil {
+		*o = arr
+		return nil
+	}
+	out := &InsAPIOutput{}
+	if err := json.Unmarshal(b, out); err != nil {
+		return err
+	}
+	*o = InsAPIOutputs{out}
+	return nil
+}
+
+// InsAPIResponse is the payload of NX-OS API response.
+type InsAPIResponse struct {
+	InsAPI struct {
+		Type    string `json:"type" xml:"type"`
+		Version string `json:"version" xml:"version"`
+		Sid     string `json:"sid" xml:"sid"`
+		Outputs struct {
+			Output InsAPIOutputs `json:"output" xml:"output"`
+		} `json:"outputs" xml:"outputs"`
+	} `json:"ins_api" xml:"ins_api"`
+}
+
+// NewInsAPIResponseFromBytes returns InsAPIResponse instance from an input
+// byte array.
+func NewInsAPIResponseFromBytes(s []byte) (*InsAPIResponse, error) {
+	resp := &InsAPIResponse{}
+	if err := json.Unmarshal(s, resp); err != nil {
+		return nil, fmt.Errorf("parsing error: %s, server response: %s", err, string(s[:]))
+	}
+	return resp, nil
+}
+
+// CliShow runs show commands with cli_show type of NX-OS API request and
+// returns their structured outputs. See InsAPI for details.
+func (cli *Client) CliShow(ctx context.Context, cmds ...string) (InsAPIOutputs, error) {
+	return cli.InsAPI(ctx, "cli_show", cmds...)
+}
+
+// CliShowArray runs show commands with cli_show_array type of NX-OS API
+// request. Unlike cli_show, the tables in the outputs are always arrays.
+func (cli *Client) CliShowArray(ctx context.Context, cmds ...string) (InsAPIOutputs, error) {
+	return cli.InsAPI(ctx, "cli_show_array", cmds...)
+}
+
+// CliShowASCII runs show commands with cli_show_ascii type of NX-OS API
+// request. Use InsAPIOutput.Text to get the text output.
+func (cli *Client) CliShowASCII(ctx context.Context, cmds ...string) (InsAPIOutputs, error) {
+	return cli.InsAPI(ctx, "cli_show_ascii", cmds...)
+}
+
+// CliConf runs configuration commands with cli_conf type of NX-OS API
+// request.
+func (cli *Client) CliConf(ctx context.Context, cmds ...string) (InsAPIOutputs, error) {
+	return cli.InsAPI(ctx, "cli_conf", cmds...)
+}
+
+// Bash runs a bash command on the device. The bash shell must be enabled
+// with "feature bash-shell".
+func (cli *Client) Bash(ctx context.Context, cmd string) (*InsAPIOutput, error) {
+	outputs, err := cli.InsAPI(ctx, "bash", cmd)
+	if len(outputs) == 0 {
+		return nil, err
+	}
+	return outputs[0], err
+}
+
+// InsAPI sends the commands, separated with " ;", in a single NX-OS API
+// request of the given type and returns an output per command. When a
+// command fails, InsAPI returns all the outputs along with the error of the
+// first failed command. The error of each command is available with
+// InsAPIOutput.Err.
+func (cli *Client) InsAPI(ctx context.Context, t string, cmds ...string) (InsAPIOutputs, error) {
+	if len(cmds) == 0 {
+		return nil, fmt.Errorf("empty input")
+	}
+	call := cli.callAPI
+	if !strings.HasPrefix(t, "cli_show") {
+		// Configuration and bash commands may change the state of the
+		// device, hence they are never retried.
+		call = cli.callAPIOnce
+	}
+	b, err := call(ctx, &Request{InsAPI: NewInsAPIRequest(strings.Join(cmds, " ;"), t)})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := NewInsAPIResponseFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	outputs := resp.InsAPI.Outputs.Output
+	for _, output := range outputs {
+		if err := output.Err(); err != nil {
+			return outputs, err
+		}
+	}
+	return outputs, nil
+}
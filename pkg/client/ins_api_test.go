@@ -0,0 +1,187 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newInsAPITestServer returns a server responding to NX-OS API requests
+// with the outputs of the captured show commands.
+func newInsAPITestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		dataDir := "../../assets/requests"
+		showCmdFileMap := map[string]string{
+			"show vpc":    "resp.show.vpc.json",
+			"show ip arp": "resp.show.ip.arp.json",
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		var j *InsAPIRequest
+		if err := json.Unmarshal(body, &j); err != nil {
+			http.Error(w, fmt.Sprintf("Bad Request, json.Unmarshal: %s", err), http.StatusBadRequest)
+			return
+		}
+		var outputs []json.RawMessage
+		for _, cmd := range strings.Split(j.Params.Input, " ;") {
+			t.Logf("server: received %s command: %s", j.Params.Type, cmd)
+			switch j.Params.Type {
+			case "cli_show":
+				fn, found := showCmdFileMap[cmd]
+				if !found {
+					outputs = append(outputs, json.RawMessage(`{"input":"`+cmd+`","msg":"Input CLI command error",`+
+						`"clierror":"% Invalid command at '^' marker.\n","code":"400"}`))
+					continue
+				}
+				fc, err := ioutil.ReadFile(dataDir + "/" + fn)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				var resp struct {
+					InsAPI struct {
+						Outputs struct {
+							Output json.RawMessage `json:"output"`
+						} `json:"outputs"`
+					} `json:"ins_api"`
+				}
+				if err := json.Unmarshal(fc, &resp); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				outputs = append(outputs, resp.InsAPI.Outputs.Output)
+			case "cli_conf":
+				outputs = append(outputs, json.RawMessage(`{"body":{},"code":"200","msg":"Success"}`))
+			case "bash":
+				outputs = append(outputs, json.RawMessage(`{"input":"`+cmd+`","body":"bootflash\n","code":"200","msg":"Success"}`))
+			}
+		}
+		output := json.RawMessage("[]")
+		if len(outputs) == 1 {
+			output = outputs[0]
+		} else if len(outputs) > 1 {
+			output, _ = json.Marshal(outputs)
+		}
+		fmt.Fprintf(w, `{"ins_api":{"type":"%s","version":"1.0","sid":"eoc","outputs":{"output":%s}}}`, j.Params.Type, output)
+	}))
+}
+
+func TestClientInsAPI(t *testing.T) {
+	server := newInsAPITestServer(t)
+	defer server.Close()
+	cli := newTestClient(server.URL)
+	ctx := context.Background()
+
+	outputs, err := cli.CliShow(ctx, "show vpc")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	var vpc ShowVpcResultBody
+	if len(outputs) != 1 || outputs[0].Decode(&vpc) != nil || vpc.VpcDomainID != "100" {
+		t.Fatalf("client: unexpected show vpc output: %#v", outputs)
+	}
+
+	outputs, err = cli.CliShow(ctx, "show vpc", "show ip arp")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(outputs) != 2 || outputs[0].Input != "show vpc" || outputs[1].Input != "show ip arp " {
+		t.Fatalf("client: unexpected outputs: %#v", outputs)
+	}
+
+	outputs, err = cli.CliShow(ctx, "show vpc", "show foo")
+	if !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("client: expected %v, got: %v", ErrInvalidCommand, err)
+	}
+	if len(outputs) != 2 || outputs[0].Err() != nil || outputs[1].Err() == nil {
+		t.Fatalf("client: expected per-output errors, got: %#v", outputs)
+	}
+
+	outputs, err = cli.CliConf(ctx, "interface e1/1", "shutdown")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(outputs) != 2 {
+		t.Fatalf("client: expected 2 outputs, got %d", len(outputs))
+	}
+
+	output, err := cli.Bash(ctx, "ls /")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if output.Text() != "bootflash\n" {
+		t.Fatalf("client: unexpected bash output: %q", output.Text())
+	}
+
+	if _, err := cli.CliShowArray(ctx); err == nil {
+		t.Fatalf("client: expected error for empty input")
+	}
+}
+
+func TestInsAPIOutputDecode(t *testing.T) {
+	dataDir := "../../assets/requests"
+
+	fc, err := ioutil.ReadFile(dataDir + "/resp.show.version.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := NewInsAPIResponseFromBytes(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var version ShowVersionResultBody
+	if err := resp.InsAPI.Outputs.Output[0].Decode(&version); err != nil {
+		t.Fatalf("decode: %s", err)
+	}
+	expVersion, err := NewShowVersionFromBytes(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(version, expVersion.InsAPI.Outputs.Output.Body) {
+		t.Fatalf("decode: unexpected show version body: %#v", version)
+	}
+
+	// The values of show environment are padded with spaces.
+	fc, err = ioutil.ReadFile(dataDir + "/resp.show.environment.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = NewInsAPIResponseFromBytes(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var environment ShowEnvironmentResultBody
+	if err := resp.InsAPI.Outputs.Output[0].Decode(&environment); err != nil {
+		t.Fatalf("decode: %s", err)
+	}
+	expEnvironment, err := NewShowEnvironmentFromBytes(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Compared in JSON, the "N/A" power draws are NaN.
+	got, _ := json.Marshal(environment)
+	exp, _ := json.Marshal(expEnvironment.InsAPI.Outputs.Output.Body)
+	if string(got) != string(exp) {
+		t.Fatalf("decode: unexpected show environment body:\ngot:  %s\nwant: %s", got, exp)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SetMaxInFlight limits the number of API calls the client sends to the
+// device concurrently. The calls over the limit wait for a slot, or for
+// their context to be done. Zero means no limit.
+func (cli *Client) SetMaxInFlight(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid max in-flight requests: %d", n)
+	}
+	cli.limitMu.Lock()
+	defer cli.limitMu.Unlock()
+	if n == 0 {
+		cli.inFlight = nil
+	} else {
+		cli.inFlight = make(chan struct{}, n)
+	}
+	return nil
+}
+
+// SetRateLimit limits the rate of the API calls the client sends to the
+// device to rps requests per second, allowing bursts of up to burst
+// requests. The calls over the budget wait for their turn, or for their
+// context to be done. Zero rps means no limit.
+func (cli *Client) SetRateLimit(rps float64, burst int) error {
+	if rps < 0 {
+		return fmt.Errorf("invalid requests per second: %v", rps)
+	}
+	if burst < 1 && rps > 0 {
+		return fmt.Errorf("invalid burst: %d", burst)
+	}
+	cli.limitMu.Lock()
+	defer cli.limitMu.Unlock()
+	if rps == 0 {
+		cli.rateLimiter = nil
+	} else {
+		cli.rateLimiter = &rateLimiter{
+			interval: time.Duration(float64(time.Second) / rps),
+			burst:    burst,
+		}
+	}
+	return nil
+}
+
+// acquire waits until the limits of the client allow another call. The
+// returned function releases the in-flight slot of the call.
+func (cli *Client) acquire(ctx context.Context) (func(), error) {
+	cli.limitMu.Lock()
+	inFlight, rl := cli.inFlight, cli.rateLimiter
+	cli.limitMu.Unlock()
+
+	release := func() {}
+	if inFlight != nil {
+		select {
+		case inFlight <- struct{}{}:
+			release = func() { <-inFlight }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if rl != nil {
+		if err := rl.wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// rateLimiter is a token bucket holding up to burst tokens, refilled at one
+// token per interval.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	// next is the time at which the bucket would be full again, had all
+	// the reserved tokens been taken.
+	next time.Time
+	// released are the reservations before next given back by the callers
+	// whose context was done, sorted. The next callers take them over.
+	released []time.Time
+}
+
+// wait takes a token from the bucket, waiting for one when it is empty. A
+// caller whose context is done before its turn gives its token back.
+func (rl *rateLimiter) wait(ctx context.Context) error {
+	rl.mu.Lock()
+	now := time.Now()
+	if rl.next.Before(now) {
+		rl.next = now
+		rl.released = nil
+	}
+	slot := rl.next
+	if len(rl.released) > 0 {
+		slot = rl.released[0]
+	}
+	delay := slot.Sub(now) - time.Duration(rl.burst-1)*rl.interval
+	if deadline, ok := ctx.Deadline(); ok && delay > 0 && deadline.Before(now.Add(delay)) {
+		// The turn would come after the deadline, the token is not taken.
+		rl.mu.Unlock()
+		return context.DeadlineExceeded
+	}
+	if len(rl.released) > 0 {
+		rl.released = rl.released[1:]
+	} else {
+		rl.next = rl.next.Add(rl.interval)
+	}
+	rl.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		rl.release(slot)
+		return ctx.Err()
+	}
+}
+
+// release gives the reservation of slot back to the bucket.
+func (rl *rateLimiter) release(slot time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if !slot.Before(rl.next) {
+		// The bucket was refilled since.
+		return
+	}
+	if slot.Add(rl.interval).Equal(rl.next) {
+		// No later reservation depends on the slot.
+		rl.next = slot
+		for n := len(rl.released); n > 0 && rl.released[n-1].Add(rl.interval).Equal(rl.next); n-- {
+			rl.next = rl.released[n-1]
+			rl.released = rl.released[:n-1]
+		}
+		return
+	}
+	i := sort.Search(len(rl.released), func(i int) bool {
+		return !rl.released[i].Before(slot)
+	})
+	rl.released = append(rl.released, time.Time{})
+	copy(rl.released[i+1:], rl.released[i:])
+	rl.released[i] = slot
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientMaxInFlight(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`))
+	}))
+	defer server.Close()
+
+	cli := newTestClient(server.URL)
+	if err := cli.SetMaxInFlight(2); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cli.GetGeneric("show clock"); err != nil {
+				t.Errorf("client: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxInFlight != 2 {
+		t.Fatalf("client: expected 2 requests in flight, got %d", maxInFlight)
+	}
+
+	// A call waiting for a slot gives up when its context is done.
+	cli.SetMaxInFlight(1)
+	cli.inFlight <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := cli.GetGenericContext(ctx, "show clock"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("client: expected context deadline exceeded, got: %v", err)
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`))
+	}))
+	defer server.Close()
+
+	cli := newTestClient(server.URL)
+	if err := cli.SetRateLimit(50, 2); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	// The first 2 calls are a burst, the next 4 wait 20ms each.
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := cli.GetGeneric("show clock"); err != nil {
+			t.Fatalf("client: %s", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Fatalf("client: 6 calls at 50 rps with a burst of 2 took %s", elapsed)
+	}
+	if err := cli.SetRateLimit(10, 0); err == nil {
+		t.Fatalf("client: expected invalid burst error")
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	rl := &rateLimiter{interval: 100 * time.Millisecond, burst: 1}
+	if err := rl.wait(context.Background()); err != nil {
+		t.Fatalf("rate limiter: %s", err)
+	}
+
+	// The callers cancelled while waiting give their tokens back.
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := rl.wait(ctx); !errors.Is(err, context.Canceled) {
+				t.Errorf("rate limiter: expected context canceled, got: %v", err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	start := time.Now()
+	if err := rl.wait(context.Background()); err != nil {
+		t.Fatalf("rate limiter: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("rate limiter: call after 5 cancelled calls waited %s", elapsed)
+	}
+
+	// A caller whose deadline comes before its turn returns at once,
+	// without taking a token.
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := rl.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("rate limiter: expected context deadline exceeded, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Fatalf("rate limiter: call past its deadline waited %s", elapsed)
+	}
+	start = time.Now()
+	if err := rl.wait(context.Background()); err != nil {
+		t.Fatalf("rate limiter: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("rate limiter: call after a deadline exceeded waited %s", elapsed)
+	}
+}
+
+func TestRateLimiterReleaseOutOfOrder(t *testing.T) {
+	start := time.Now()
+	rl := &rateLimiter{interval: time.Second, burst: 1, next: start.Add(4 * time.Second)}
+	// The reservations at 1s and 2s are given back, the one at 3s still
+	// holds.
+	rl.release(start.Add(time.Second))
+	rl.release(start.Add(2 * time.Second))
+	if len(rl.released) != 2 || !rl.next.Equal(start.Add(4*time.Second)) {
+		t.Fatalf("rate limiter: unexpected state, next %s, released %v", rl.next.Sub(start), rl.released)
+	}
+	// Once the one at 3s is given back too, the bucket is refilled up to
+	// the reservation at 0s.
+	rl.release(start.Add(3 * time.Second))
+	if len(rl.released) != 0 || !rl.next.Equal(start.Add(time.Second)) {
+		t.Fatalf("rate limiter: unexpected state, next %s, released %v", rl.next.Sub(start), rl.released)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Request is an API call of the client, as seen by its middleware. Exactly
+// one of JSONRPC and InsAPI is set. Middleware may modify the request in
+// place, e.g. to add headers, before passing it on.
+type Request struct {
+	// Host is the device the request is sent to.
+	Host string
+	// JSONRPC is the payload of JSON-RPC requests, one element per command.
+	JSONRPC []*JSONRPCRequest
+	// InsAPI is the payload of NX-OS API requests.
+	InsAPI *InsAPIRequest
+	// Header holds additional HTTP headers of the request.
+	Header http.Header
+
+	session *http.Cookie
+}
+
+// ContentType returns the content type of the request payload: "jsonrpc",
+// "json" or "xml".
+func (r *Request) ContentType() string {
+	if r.InsAPI != nil {
+		if r.InsAPI.Params.Format == OutputFormatXML {
+			return "xml"
+		}
+		return "json"
+	}
+	return "jsonrpc"
+}
+
+// Commands returns the commands of the request.
+func (r *Request) Commands() []string {
+	if r.InsAPI != nil {
+		return strings.Split(r.InsAPI.Params.Input, " ;")
+	}
+	var cmds []string
+	for _, req := range r.JSONRPC {
+		cmds = append(cmds, req.Params.Command)
+	}
+	return cmds
+}
+
+// Payload returns the body of the HTTP request.
+func (r *Request) Payload() ([]byte, error) {
+	switch r.ContentType() {
+	case "xml":
+		payload, err := xml.Marshal(r.InsAPI)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(xml.Header), payload...), nil
+	case "json":
+		return json.Marshal(r.InsAPI)
+	}
+	if len(r.JSONRPC) == 0 {
+		return nil, fmt.Errorf("empty request")
+	}
+	return json.Marshal(r.JSONRPC)
+}
+
+// Response is the raw response to a Request. Middleware may modify the
+// body, e.g. to redact it, before returning it.
+type Response struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+// RoundTripFunc sends a request to the device and returns its response. On
+// HTTP errors, e.g. "401 Authorization Required", both the response and
+// *HTTPError are returned.
+type RoundTripFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware intercepts the requests of the client. It returns a
+// RoundTripFunc, which typically inspects or modifies the request, calls
+// next and inspects or modifies the response.
+//
+//	cli.Use(func(next client.RoundTripFunc) client.RoundTripFunc {
+//		return func(ctx context.Context, req *client.Request) (*client.Response, error) {
+//			start := time.Now()
+//			resp, err := next(ctx, req)
+//			log.Printf("%s %v: %s, %v", req.Host, req.Commands(), time.Since(start), err)
+//			return resp, err
+//		}
+//	})
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Use appends middleware to the chain of the client. The middleware added
+// first is the outermost one. Each HTTP request passes through the chain,
+// including retries and re-authentication after an expired session.
+func (cli *Client) Use(mw ...Middleware) {
+	cli.middlewareMu.Lock()
+	defer cli.middlewareMu.Unlock()
+	cli.middleware = append(cli.middleware, mw...)
+}
+
+// roundTrip sends the request through the middleware chain.
+func (cli *Client) roundTrip(ctx context.Context, r *Request) (*Response, error) {
+	cli.middlewareMu.Lock()
+	mws := cli.middleware
+	cli.middlewareMu.Unlock()
+
+	rt := RoundTripFunc(cli.send)
+	if cli.ssh != nil {
+		rt = cli.ssh.roundTrip
+	}
+	for i := len(mws) - 1; i >= 0; i-- {
+		rt = mws[i](rt)
+	}
+	return rt(ctx, r)
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClientMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		if req.Header.Get("X-Request-Id") != "42" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("<html><head><title>401 Authorization Required</title></head></html>"))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{"simple_time":"secret"}},"id":1}`))
+	}))
+	defer server.Close()
+
+	type call struct {
+		Host       string
+		Commands   []string
+		StatusCode int
+		Bytes      int
+	}
+	var calls []call
+	var order []string
+	cli := newTestClient(server.URL)
+	cli.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			order = append(order, "trace")
+			resp, err := next(ctx, req)
+			c := call{Host: req.Host, Commands: req.Commands()}
+			if resp != nil {
+				c.StatusCode, c.Bytes = resp.StatusCode, len(resp.Body)
+			}
+			calls = append(calls, c)
+			return resp, err
+		}
+	})
+
+	// The device rejects the request without the header.
+	if _, err := cli.GetGeneric("show clock"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("client: expected unauthorized error, got: %v", err)
+	}
+
+	cli.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			order = append(order, "header")
+			req.Header = http.Header{"X-Request-Id": []string{"42"}}
+			resp, err := next(ctx, req)
+			if resp != nil {
+				resp.Body = bytes.Replace(resp.Body, []byte("secret"), []byte("******"), -1)
+			}
+			return resp, err
+		}
+	})
+	resp, err := cli.GetGeneric("show clock")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if !bytes.Contains(resp, []byte("******")) || bytes.Contains(resp, []byte("secret")) {
+		t.Fatalf("client: response not redacted: %s", resp)
+	}
+
+	host := cli.host
+	expCalls := []call{
+		{Host: host, Commands: []string{"show clock"}, StatusCode: 401, Bytes: 67},
+		{Host: host, Commands: []string{"show clock"}, StatusCode: 200, Bytes: 67},
+	}
+	if !reflect.DeepEqual(calls, expCalls) {
+		t.Fatalf("client: unexpected calls:\n%+v\nexpected:\n%+v", calls, expCalls)
+	}
+	if !reflect.DeepEqual(order, []string{"trace", "trace", "header"}) {
+		t.Fatalf("client: unexpected middleware order: %v", order)
+	}
+}
@@ -0,0 +1,414 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultNETCONFPort is the port of the NETCONF SSH subsystem.
+	DefaultNETCONFPort = 830
+
+	netconfBase10 = "urn:ietf:params:netconf:base:1.0"
+	netconfBase11 = "urn:ietf:params:netconf:base:1.1"
+	netconfNS     = "urn:ietf:params:xml:ns:netconf:base:1.0"
+	// netconfEOM is the end-of-message delimiter of NETCONF 1.0.
+	netconfEOM = "]]>]]>"
+)
+
+// Datastore is a configuration datastore of NETCONF.
+type Datastore string
+
+// The datastores of NX-OS.
+const (
+	Running   Datastore = "running"
+	Candidate Datastore = "candidate"
+	Startup   Datastore = "startup"
+)
+
+// NETCONFError is an rpc-error returned by the NETCONF server.
+type NETCONFError struct {
+	Type     string `xml:"error-type"`
+	Tag      string `xml:"error-tag"`
+	Severity string `xml:"error-severity"`
+	Path     string `xml:"error-path"`
+	Message  string `xml:"error-message"`
+}
+
+func (e *NETCONFError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("netconf %s error: %s: %s", e.Type, e.Tag, strings.TrimSpace(e.Message))
+	}
+	return fmt.Sprintf("netconf %s error: %s", e.Type, e.Tag)
+}
+
+// Is reports whether the error matches one of the errors of this package.
+func (e *NETCONFError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Tag == "access-denied"
+	case ErrCommandNotSupported:
+		return e.Tag == "operation-not-supported"
+	case ErrInvalidRequest:
+		switch e.Tag {
+		case "invalid-value", "bad-attribute", "bad-element", "unknown-attribute",
+			"unknown-element", "unknown-namespace", "missing-attribute", "missing-element":
+			return true
+		}
+	}
+	return false
+}
+
+// NETCONFSession is a NETCONF session with the device, over SSH. It is
+// safe for concurrent use, the RPCs are sent one at a time.
+type NETCONFSession struct {
+	// SessionID is the session-id assigned by the device.
+	SessionID string
+	// Capabilities are the capabilities advertised by the device.
+	Capabilities []string
+
+	mu      sync.Mutex
+	client  *ssh.Client
+	session *ssh.Session
+	framer  *netconfFramer
+	msgID   uint64
+	closed  bool
+}
+
+// DialNETCONF opens a NETCONF session with the device on the port, e.g.
+// DefaultNETCONFPort, using the host, credentials and dialer of the
+// client. The host key of the device is checked with hostKey, as in
+// SetSSHTransport. The session is independent of the NX-API calls of the
+// client, e.g. Configure, and must be closed by the caller.
+func (cli *Client) DialNETCONF(ctx context.Context, port int, hostKey ssh.HostKeyCallback) (*NETCONFSession, error) {
+	hostKey, err := cli.sshHostKeyCallback(hostKey)
+	if err != nil {
+		return nil, err
+	}
+	client, err := cli.dialSSH(ctx, port, hostKey)
+	if err != nil {
+		return nil, fmt.Errorf("netconf: %w", err)
+	}
+	s := &NETCONFSession{client: client}
+	if err := s.open(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+type netconfHello struct {
+	XMLName      xml.Name `xml:"hello"`
+	Capabilities []string `xml:"capabilities>capability"`
+	SessionID    string   `xml:"session-id,omitempty"`
+}
+
+// open starts the netconf subsystem and exchanges the hello messages.
+func (s *NETCONFSession) open(ctx context.Context) error {
+	session, err := s.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("netconf: %w", err)
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := session.RequestSubsystem("netconf"); err != nil {
+		return fmt.Errorf("netconf: %w", err)
+	}
+	s.session = session
+	s.framer = &netconfFramer{r: bufio.NewReader(stdout), w: stdin}
+
+	hello, err := xml.Marshal(&netconfHello{Capabilities: []string{netconfBase10, netconfBase11}})
+	if err != nil {
+		return err
+	}
+	var b []byte
+	err = s.exchange(ctx, func() error {
+		if err := s.framer.writeMessage(append([]byte(xml.Header), hello...)); err != nil {
+			return err
+		}
+		b, err = s.framer.readMessage()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	peer := &netconfHello{}
+	if err := xml.Unmarshal(b, peer); err != nil {
+		return fmt.Errorf("netconf: invalid hello: %s", err)
+	}
+	s.SessionID = peer.SessionID
+	s.Capabilities = peer.Capabilities
+	// The chunked framing of NETCONF 1.1 is used when both peers support it.
+	for _, c := range peer.Capabilities {
+		if strings.TrimSpace(c) == netconfBase11 {
+			s.framer.chunked = true
+		}
+	}
+	return nil
+}
+
+// exchange runs fn, closing the session when the context is done before fn
+// returns, to unblock its reads and writes.
+func (s *NETCONFSession) exchange(ctx context.Context, fn func() error) error {
+	done := make(chan struct{})
+	defer close(done)
+	session := s.session
+	go func() {
+		select {
+		case <-ctx.Done():
+			session.Close()
+		case <-done:
+		}
+	}()
+	if err := fn(); err != nil {
+		s.closed = true
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("netconf: %w", err)
+	}
+	return nil
+}
+
+type netconfReply struct {
+	XMLName   xml.Name        `xml:"rpc-reply"`
+	MessageID string          `xml:"message-id,attr"`
+	OK        *struct{}       `xml:"ok"`
+	Data      *netconfData    `xml:"data"`
+	Errors    []*NETCONFError `xml:"rpc-error"`
+	raw       []byte
+}
+
+type netconfData struct {
+	Inner []byte `xml:",innerxml"`
+}
+
+// Do sends an RPC with the operation, e.g. "<get/>", and returns the
+// rpc-reply message. It returns *NETCONFError when the device reports an
+// error.
+func (s *NETCONFSession) Do(ctx context.Context, operation string) ([]byte, error) {
+	reply, err := s.rpc(ctx, operation)
+	if err != nil {
+		return nil, err
+	}
+	return reply.raw, nil
+}
+
+// rpc sends the operation and returns the decoded rpc-reply.
+func (s *NETCONFSession) rpc(ctx context.Context, operation string) (*netconfReply, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, fmt.Errorf("netconf: session closed")
+	}
+	s.msgID++
+	id := strconv.FormatUint(s.msgID, 10)
+	msg := fmt.Sprintf(`<rpc message-id="%s" xmlns="%s">%s</rpc>`, id, netconfNS, operation)
+
+	var b []byte
+	err := s.exchange(ctx, func() error {
+		if err := s.framer.writeMessage([]byte(msg)); err != nil {
+			return err
+		}
+		var err error
+		b, err = s.framer.readMessage()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	reply := &netconfReply{raw: b}
+	if err := xml.Unmarshal(b, reply); err != nil {
+		return nil, fmt.Errorf("netconf: parsing error: %s, server response: %s", err, b)
+	}
+	if reply.MessageID != "" && reply.MessageID != id {
+		return nil, fmt.Errorf("netconf: unexpected message-id %s, expected %s", reply.MessageID, id)
+	}
+	for _, e := range reply.Errors {
+		if e.Severity != "warning" {
+			return nil, e
+		}
+	}
+	return reply, nil
+}
+
+// data sends the operation and returns the content of the data element of
+// the reply.
+func (s *NETCONFSession) data(ctx context.Context, operation string) ([]byte, error) {
+	reply, err := s.rpc(ctx, operation)
+	if err != nil {
+		return nil, err
+	}
+	if reply.Data == nil {
+		return nil, fmt.Errorf("netconf: reply has no data")
+	}
+	return reply.Data.Inner, nil
+}
+
+func subtreeFilter(filter string) string {
+	if filter == "" {
+		return ""
+	}
+	return `<filter type="subtree">` + filter + `</filter>`
+}
+
+// Get retrieves the running configuration and the state data, selected
+// with the subtree filter, e.g. `<System
+// xmlns="http://cisco.com/ns/yang/cisco-nx-os-device"><bd-items/></System>`.
+// An empty filter selects all data. It returns the content of the data
+// element of the reply.
+func (s *NETCONFSession) Get(ctx context.Context, filter string) ([]byte, error) {
+	return s.data(ctx, "<get>"+subtreeFilter(filter)+"</get>")
+}
+
+// GetConfig retrieves the configuration of the datastore, selected with the
+// subtree filter.
+func (s *NETCONFSession) GetConfig(ctx context.Context, source Datastore, filter string) ([]byte, error) {
+	return s.data(ctx, fmt.Sprintf("<get-config><source><%s/></source>%s</get-config>", source, subtreeFilter(filter)))
+}
+
+// EditConfig merges the config, i.e. the content of the config element,
+// into the target datastore.
+func (s *NETCONFSession) EditConfig(ctx context.Context, target Datastore, config string) error {
+	_, err := s.rpc(ctx, fmt.Sprintf("<edit-config><target><%s/></target><config>%s</config></edit-config>", target, config))
+	return err
+}
+
+// Lock locks the datastore, so that other sessions, and the CLI, cannot
+// change it.
+func (s *NETCONFSession) Lock(ctx context.Context, target Datastore) error {
+	_, err := s.rpc(ctx, fmt.Sprintf("<lock><target><%s/></target></lock>", target))
+	return err
+}
+
+// Unlock releases the lock of the datastore.
+func (s *NETCONFSession) Unlock(ctx context.Context, target Datastore) error {
+	_, err := s.rpc(ctx, fmt.Sprintf("<unlock><target><%s/></target></unlock>", target))
+	return err
+}
+
+// Commit commits the candidate configuration to the running one.
+func (s *NETCONFSession) Commit(ctx context.Context) error {
+	_, err := s.rpc(ctx, "<commit/>")
+	return err
+}
+
+// DiscardChanges reverts the candidate configuration to the running one.
+func (s *NETCONFSession) DiscardChanges(ctx context.Context) error {
+	_, err := s.rpc(ctx, "<discard-changes/>")
+	return err
+}
+
+// Close ends the NETCONF session gracefully and closes the connection.
+func (s *NETCONFSession) Close() error {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	var err error
+	if !closed {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultDialTimeout)
+		_, err = s.rpc(ctx, "<close-session/>")
+		cancel()
+	}
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.session.Close()
+	s.client.Close()
+	return err
+}
+
+// netconfFramer reads and writes the messages of a NETCONF session, either
+// delimited with "]]>]]>" (NETCONF 1.0) or in chunks (NETCONF 1.1).
+type netconfFramer struct {
+	r       *bufio.Reader
+	w       io.Writer
+	chunked bool
+}
+
+func (f *netconfFramer) writeMessage(b []byte) error {
+	var err error
+	if f.chunked {
+		_, err = fmt.Fprintf(f.w, "\n#%d\n%s\n##\n", len(b), b)
+	} else {
+		_, err = fmt.Fprintf(f.w, "%s%s", b, netconfEOM)
+	}
+	return err
+}
+
+func (f *netconfFramer) readMessage() ([]byte, error) {
+	if f.chunked {
+		return f.readChunkedMessage()
+	}
+	var buf bytes.Buffer
+	for {
+		c, err := f.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(c)
+		if c == '>' && bytes.HasSuffix(buf.Bytes(), []byte(netconfEOM)) {
+			buf.Truncate(buf.Len() - len(netconfEOM))
+			return bytes.TrimSpace(buf.Bytes()), nil
+		}
+	}
+}
+
+// readChunkedMessage reads the chunks of a message, as per RFC 6242:
+//
+//	\n#<size>\n<data>...\n##\n
+func (f *netconfFramer) readChunkedMessage() ([]byte, error) {
+	var buf bytes.Buffer
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(f.r, header); err != nil {
+			return nil, err
+		}
+		if header[0] != '\n' || header[1] != '#' {
+			return nil, fmt.Errorf("invalid chunk header %q", header)
+		}
+		line, err := f.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "#" {
+			return buf.Bytes(), nil
+		}
+		size, err := strconv.ParseUint(line, 10, 32)
+		if err != nil || size == 0 {
+			return nil, fmt.Errorf("invalid chunk size %q", line)
+		}
+		if _, err := io.CopyN(&buf, f.r, int64(size)); err != nil {
+			return nil, err
+		}
+	}
+}
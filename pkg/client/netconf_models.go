@@ -0,0 +1,167 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// NXOSDeviceNamespace is the namespace of the NX-OS device YANG model,
+// which mirrors the DME object model.
+const NXOSDeviceNamespace = "http://cisco.com/ns/yang/cisco-nx-os-device"
+
+// nxosSystem is the System container of the NX-OS device YANG model,
+// limited to the lists mapped onto Interface and Vlan.
+type nxosSystem struct {
+	XMLName xml.Name      `xml:"System"`
+	PhysIfs []*nxosPhysIf `xml:"intf-items>phys-items>PhysIf-list"`
+	BDs     []*nxosBD     `xml:"bd-items>bd-items>BD-list"`
+}
+
+type nxosPhysIf struct {
+	ID      string `xml:"id"`
+	Descr   string `xml:"descr"`
+	AdminSt string `xml:"adminSt"`
+	Mode    string `xml:"mode"`
+	Medium  string `xml:"medium"`
+	Mtu     uint64 `xml:"mtu"`
+	Phys    struct {
+		OperSt       string `xml:"operSt"`
+		OperSpeed    string `xml:"operSpeed"`
+		OperDuplex   string `xml:"operDuplex"`
+		BackplaneMac string `xml:"backplaneMac"`
+	} `xml:"phys-items"`
+	In struct {
+		Octets        uint64 `xml:"inOctets"`
+		UcastPkts     uint64 `xml:"inUcastPkts"`
+		MulticastPkts uint64 `xml:"inMulticastPkts"`
+		BroadcastPkts uint64 `xml:"inBroadcastPkts"`
+		Discards      uint64 `xml:"inDiscards"`
+		Errors        uint64 `xml:"inErrors"`
+	} `xml:"dbgIfIn-items"`
+	Out struct {
+		Octets        uint64 `xml:"outOctets"`
+		UcastPkts     uint64 `xml:"outUcastPkts"`
+		MulticastPkts uint64 `xml:"outMulticastPkts"`
+		BroadcastPkts uint64 `xml:"outBroadcastPkts"`
+		Discards      uint64 `xml:"outDiscards"`
+		Errors        uint64 `xml:"outErrors"`
+	} `xml:"dbgIfOut-items"`
+}
+
+type nxosBD struct {
+	ID       string `xml:"id"`
+	FabEncap string `xml:"fabEncap"`
+	Name     string `xml:"name"`
+	AdminSt  string `xml:"adminSt"`
+	BdState  string `xml:"BdState"`
+	Mode     string `xml:"mode"`
+}
+
+// GetInterfaces returns the physical interfaces of the device, read from
+// the NX-OS device YANG model. Only the fields present in the model are
+// set, e.g. the states, MTU, speed and the counters of the interface.
+func (s *NETCONFSession) GetInterfaces(ctx context.Context) ([]*Interface, error) {
+	sys, err := s.getSystem(ctx, "<intf-items><phys-items/></intf-items>")
+	if err != nil {
+		return nil, err
+	}
+	var ifaces []*Interface
+	for i, p := range sys.PhysIfs {
+		intf := &Interface{}
+		intf.Name = p.ID
+		if strings.HasPrefix(p.ID, "eth") {
+			// The model names interfaces "eth1/1", the CLI "Ethernet1/1".
+			intf.Name = "Ethernet" + strings.TrimPrefix(p.ID, "eth")
+		}
+		intf.LocalIndex = i
+		intf.Description = p.Descr
+		intf.Props.AdminState = p.AdminSt
+		intf.Props.State = p.Phys.OperSt
+		intf.Props.MTU = p.Mtu
+		intf.Props.Mode = p.Mode
+		intf.Props.Medium = p.Medium
+		intf.Props.Speed = p.Phys.OperSpeed
+		intf.Props.Duplex = p.Phys.OperDuplex
+		intf.Props.HwAddr = p.Phys.BackplaneMac
+		intf.Counters.InputBytes = p.In.Octets
+		intf.Counters.InputUnicastPackets = p.In.UcastPkts
+		intf.Counters.InputMulticastPackets = p.In.MulticastPkts
+		intf.Counters.InputBroadcastPackets = p.In.BroadcastPkts
+		intf.Counters.InputPackets = p.In.UcastPkts + p.In.MulticastPkts + p.In.BroadcastPkts
+		intf.Counters.InputDiscards = p.In.Discards
+		intf.Counters.InputErrors = p.In.Errors
+		intf.Counters.OutputBytes = p.Out.Octets
+		intf.Counters.OutputUnicastPackets = p.Out.UcastPkts
+		intf.Counters.OutputMulticastPackets = p.Out.MulticastPkts
+		intf.Counters.OutputBroadcastPackets = p.Out.BroadcastPkts
+		intf.Counters.OutputPackets = p.Out.UcastPkts + p.Out.MulticastPkts + p.Out.BroadcastPkts
+		intf.Counters.OutputDiscards = p.Out.Discards
+		intf.Counters.OutputErrors = p.Out.Errors
+		ifaces = append(ifaces, intf)
+	}
+	return ifaces, nil
+}
+
+// GetVlans returns the VLANs of the device, i.e. the bridge domains of the
+// NX-OS device YANG model. The model has no member ports of the VLANs.
+func (s *NETCONFSession) GetVlans(ctx context.Context) ([]*Vlan, error) {
+	sys, err := s.getSystem(ctx, "<bd-items><bd-items/></bd-items>")
+	if err != nil {
+		return nil, err
+	}
+	var vlans []*Vlan
+	for _, bd := range sys.BDs {
+		vlan := &Vlan{}
+		vlan.ID = bd.ID
+		if vlan.ID == "" {
+			vlan.ID = strings.TrimPrefix(bd.FabEncap, "vlan-")
+		}
+		vlan.Name = bd.Name
+		vlan.State = bd.BdState
+		if vlan.State == "" {
+			vlan.State = bd.AdminSt
+		}
+		if bd.Mode != "" {
+			// "CE" in the model, "ce-vlan" in the CLI.
+			vlan.Mode = strings.ToLower(bd.Mode) + "-vlan"
+		}
+		vlan.Ports = []string{}
+		vlans = append(vlans, vlan)
+	}
+	return vlans, nil
+}
+
+// getSystem gets the subtree of the System container.
+func (s *NETCONFSession) getSystem(ctx context.Context, subtree string) (*nxosSystem, error) {
+	filter := fmt.Sprintf(`<System xmlns="%s">%s</System>`, NXOSDeviceNamespace, subtree)
+	b, err := s.Get(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sys := &nxosSystem{}
+	if len(bytes.TrimSpace(b)) == 0 {
+		// No data matches the filter.
+		return sys, nil
+	}
+	if err := xml.Unmarshal(b, sys); err != nil {
+		return nil, fmt.Errorf("netconf: parsing error: %s", err)
+	}
+	return sys, nil
+}
@@ -0,0 +1,301 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const netconfTestInterfaces = `<data><System xmlns="http://cisco.com/ns/yang/cisco-nx-os-device"><intf-items><phys-items>
+<PhysIf-list><id>eth1/1</id><adminSt>up</adminSt><descr>uplink</descr><mode>trunk</mode><medium>broadcast</medium><mtu>9216</mtu>
+<phys-items><operSt>up</operSt><operSpeed>10G</operSpeed><operDuplex>full</operDuplex><backplaneMac>00:3a:9c:5b:4c:08</backplaneMac></phys-items>
+<dbgIfIn-items><inOctets>1000</inOctets><inUcastPkts>10</inUcastPkts><inMulticastPkts>2</inMulticastPkts><inBroadcastPkts>1</inBroadcastPkts><inErrors>3</inErrors></dbgIfIn-items>
+<dbgIfOut-items><outOctets>2000</outOctets><outUcastPkts>20</outUcastPkts></dbgIfOut-items>
+</PhysIf-list>
+<PhysIf-list><id>eth1/2</id><adminSt>down</adminSt><mtu>1500</mtu><phys-items><operSt>down</operSt></phys-items></PhysIf-list>
+</phys-items></intf-items></System></data>`
+
+const netconfTestVlans = `<data><System xmlns="http://cisco.com/ns/yang/cisco-nx-os-device"><bd-items><bd-items>
+<BD-list><fabEncap>vlan-1</fabEncap><id>1</id><name>default</name><adminSt>active</adminSt><BdState>active</BdState><mode>CE</mode></BD-list>
+<BD-list><fabEncap>vlan-2345</fabEncap><name>VLAN2345</name><adminSt>suspend</adminSt><mode>CE</mode></BD-list>
+</bd-items></bd-items></System></data>`
+
+// netconfTestServer is a stand-in of the NETCONF server of NX-OS, with a
+// running and a candidate datastore.
+type netconfTestServer struct {
+	base11 bool
+
+	mu        sync.Mutex
+	locked    bool
+	running   string
+	candidate string
+}
+
+func (n *netconfTestServer) serve(name string, channel ssh.Channel) {
+	defer channel.Close()
+	if name != "netconf" {
+		return
+	}
+	f := &netconfFramer{r: bufio.NewReader(channel), w: channel}
+	caps := []string{netconfBase10, "urn:ietf:params:netconf:capability:candidate:1.0"}
+	if n.base11 {
+		caps = append(caps, netconfBase11)
+	}
+	hello, _ := xml.Marshal(&netconfHello{Capabilities: caps, SessionID: "42"})
+	if err := f.writeMessage(hello); err != nil {
+		return
+	}
+	b, err := f.readMessage()
+	if err != nil {
+		return
+	}
+	peer := &netconfHello{}
+	if err := xml.Unmarshal(b, peer); err != nil {
+		return
+	}
+	for _, c := range peer.Capabilities {
+		if n.base11 && c == netconfBase11 {
+			f.chunked = true
+		}
+	}
+	for {
+		b, err := f.readMessage()
+		if err != nil {
+			return
+		}
+		var rpc struct {
+			MessageID  string `xml:"message-id,attr"`
+			Operations []struct {
+				XMLName xml.Name
+				Inner   string `xml:",innerxml"`
+			} `xml:",any"`
+		}
+		if err := xml.Unmarshal(b, &rpc); err != nil || len(rpc.Operations) != 1 {
+			return
+		}
+		op := rpc.Operations[0]
+		reply := n.reply(op.XMLName.Local, op.Inner)
+		msg := fmt.Sprintf(`<rpc-reply message-id="%s" xmlns="%s">%s</rpc-reply>`, rpc.MessageID, netconfNS, reply)
+		if err := f.writeMessage([]byte(msg)); err != nil {
+			return
+		}
+		if op.XMLName.Local == "close-session" {
+			return
+		}
+	}
+}
+
+func (n *netconfTestServer) reply(op, inner string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	switch op {
+	case "get":
+		switch {
+		case strings.Contains(inner, "<phys-items/>"):
+			return netconfTestInterfaces
+		case strings.Contains(inner, "<bd-items/>"):
+			return netconfTestVlans
+		}
+		return "<data/>"
+	case "get-config":
+		if strings.Contains(inner, "<candidate/>") {
+			return "<data>" + n.candidate + "</data>"
+		}
+		return "<data>" + n.running + "</data>"
+	case "edit-config":
+		if !strings.Contains(inner, "<candidate/>") {
+			return netconfTestError("protocol", "operation-not-supported", "edit the candidate")
+		}
+		i := strings.Index(inner, "<config>")
+		j := strings.LastIndex(inner, "</config>")
+		n.candidate = inner[i+len("<config>") : j]
+		return "<ok/>"
+	case "lock":
+		if n.locked {
+			return netconfTestError("protocol", "lock-denied", "Lock failed, lock is already held")
+		}
+		n.locked = true
+		return "<ok/>"
+	case "unlock":
+		n.locked = false
+		return "<ok/>"
+	case "commit":
+		n.running = n.candidate
+		return "<ok/>"
+	case "discard-changes":
+		n.candidate = n.running
+		return "<ok/>"
+	case "close-session":
+		return "<ok/>"
+	}
+	return netconfTestError("protocol", "operation-not-supported", "")
+}
+
+func netconfTestError(typ, tag, msg string) string {
+	return fmt.Sprintf("<rpc-error><error-type>%s</error-type><error-tag>%s</error-tag>"+
+		"<error-severity>error</error-severity><error-message>%s</error-message></rpc-error>", typ, tag, msg)
+}
+
+func TestNETCONFSession(t *testing.T) {
+	for _, base11 := range []bool{false, true} {
+		t.Run(fmt.Sprintf("base11=%t", base11), func(t *testing.T) {
+			testNETCONFSession(t, base11)
+		})
+	}
+}
+
+func testNETCONFSession(t *testing.T, base11 bool) {
+	server := newSSHTestServer(t)
+	defer server.close()
+	netconf := &netconfTestServer{base11: base11, running: "<System><name>switch</name></System>"}
+	server.subsystem = netconf.serve
+
+	cli, err := New("127.0.0.1", WithProtocol("http"), WithCredentials("admin", "cisco"))
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	ctx := context.Background()
+	s, err := cli.DialNETCONF(ctx, server.port(), nil)
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if s.SessionID != "42" {
+		t.Fatalf("client: expected session-id 42, got %q", s.SessionID)
+	}
+	if s.framer.chunked != base11 {
+		t.Fatalf("client: expected chunked framing %t, got %t", base11, s.framer.chunked)
+	}
+
+	ifaces, err := s.GetInterfaces(ctx)
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(ifaces) != 2 {
+		t.Fatalf("client: expected 2 interfaces, got %d", len(ifaces))
+	}
+	intf := ifaces[0]
+	if intf.Name != "Ethernet1/1" || intf.Description != "uplink" || intf.Props.AdminState != "up" ||
+		intf.Props.State != "up" || intf.Props.MTU != 9216 || intf.Props.Speed != "10G" {
+		t.Fatalf("client: unexpected interface: %+v", intf.Props)
+	}
+	if intf.Counters.InputPackets != 13 || intf.Counters.InputErrors != 3 || intf.Counters.OutputBytes != 2000 {
+		t.Fatalf("client: unexpected counters: %+v", intf.Counters)
+	}
+	if ifaces[1].Name != "Ethernet1/2" || ifaces[1].Props.State != "down" {
+		t.Fatalf("client: unexpected interface %s: %+v", ifaces[1].Name, ifaces[1].Props)
+	}
+
+	vlans, err := s.GetVlans(ctx)
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(vlans) != 2 {
+		t.Fatalf("client: expected 2 vlans, got %d", len(vlans))
+	}
+	if vlans[0].ID != "1" || vlans[0].Name != "default" || vlans[0].State != "active" || vlans[0].Mode != "ce-vlan" {
+		t.Fatalf("client: unexpected vlan: %+v", vlans[0])
+	}
+	if vlans[1].ID != "2345" || vlans[1].State != "suspend" {
+		t.Fatalf("client: unexpected vlan: %+v", vlans[1])
+	}
+
+	if err := s.Lock(ctx, Candidate); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	var nerr *NETCONFError
+	if err := s.Lock(ctx, Candidate); !errors.As(err, &nerr) || nerr.Tag != "lock-denied" {
+		t.Fatalf("client: expected lock-denied, got %v", err)
+	}
+	config := `<System xmlns="http://cisco.com/ns/yang/cisco-nx-os-device"><name>nysw01</name></System>`
+	if err := s.EditConfig(ctx, Candidate, config); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if err := s.Commit(ctx); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if err := s.Unlock(ctx, Candidate); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	running, err := s.GetConfig(ctx, Running, "")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if string(running) != config {
+		t.Fatalf("client: expected running config %s, got %s", config, running)
+	}
+
+	if _, err := s.Do(ctx, "<get-schema/>"); !errors.Is(err, ErrCommandNotSupported) {
+		t.Fatalf("client: expected %v, got %v", ErrCommandNotSupported, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if _, err := s.Get(ctx, ""); err == nil {
+		t.Fatalf("client: expected closed session to fail")
+	}
+}
+
+func TestNETCONFFramer(t *testing.T) {
+	testcases := []struct {
+		name    string
+		chunked bool
+		input   string
+		want    []string
+		err     bool
+	}{
+		{
+			name:  "end-of-message framing",
+			input: "<rpc-reply><ok/></rpc-reply>]]>]]>\n<rpc-reply><data/></rpc-reply>]]>]]>",
+			want:  []string{"<rpc-reply><ok/></rpc-reply>", "<rpc-reply><data/></rpc-reply>"},
+		},
+		{
+			name:    "chunked framing with multiple chunks",
+			chunked: true,
+			input:   "\n#4\n<rpc\n#18\n message-id=\"102\"\n\n#10\n><ok/></rp\n#3\nc>\n\n##\n",
+			want:    []string{"<rpc message-id=\"102\"\n><ok/></rpc>\n"},
+		},
+		{
+			name:    "chunked framing with invalid size",
+			chunked: true,
+			input:   "\n#abc\n<ok/>\n##\n",
+			err:     true,
+		},
+	}
+	for _, tc := range testcases {
+		f := &netconfFramer{r: bufio.NewReader(strings.NewReader(tc.input)), chunked: tc.chunked}
+		for _, want := range tc.want {
+			got, err := f.readMessage()
+			if err != nil {
+				t.Fatalf("%s: %s", tc.name, err)
+			}
+			if string(got) != want {
+				t.Fatalf("%s: expected %q, got %q", tc.name, want, got)
+			}
+		}
+		if tc.err {
+			if _, err := f.readMessage(); err == nil {
+				t.Fatalf("%s: expected an error", tc.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"net/http"
+	"time"
+)
+
+// Logger is the interface of the loggers of the client, e.g. *log.Logger or
+// a logrus logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Option configures a Client built with New.
+type Option func(*Client) error
+
+// New returns an instance of Client for the host, configured with the
+// options, e.g.
+//
+//	cli, err := client.New("nysw01",
+//		client.WithCredentials("admin", "cisco"),
+//		client.WithTimeout(10*time.Second),
+//	)
+//
+// Unless set otherwise, the client uses https and the default port of the
+// protocol. New validates the combination of the options, e.g. it rejects
+// http on port 443. The setters of the client keep working on the returned
+// instance.
+func New(host string, opts ...Option) (*Client, error) {
+	cli := NewClient()
+	if err := cli.SetHost(host); err != nil {
+		return nil, err
+	}
+	cli.port = 0
+	for _, opt := range opts {
+		if err := opt(cli); err != nil {
+			return nil, err
+		}
+	}
+	if err := cli.validate(); err != nil {
+		return nil, err
+	}
+	return cli, nil
+}
+
+// validate checks the settings of a client built with New, and fills in
+// the defaults depending on other settings.
+func (cli *Client) validate() error {
+	switch {
+	case cli.port == 0 && cli.protocol == "http":
+		cli.port = 80
+	case cli.port == 0:
+		cli.port = 443
+	case cli.protocol == "http" && cli.port == 443:
+		return fmt.Errorf("protocol http on port 443, the https port")
+	case cli.protocol == "https" && cli.port == 80:
+		return fmt.Errorf("protocol https on port 80, the http port")
+	}
+	if cli.protocol == "http" && (cli.secure || len(cli.certificates) > 0 || cli.serverName != "") {
+		return fmt.Errorf("tls options with protocol http")
+	}
+	if cli.credentialProvider == nil && (cli.username == "") != (cli.password == "") {
+		return fmt.Errorf("username and password must be set together")
+	}
+	return nil
+}
+
+// WithPort sets the port of the API calls.
+func WithPort(p int) Option {
+	return func(cli *Client) error {
+		if p < 1 || p > 65535 {
+			return fmt.Errorf("invalid port: %d", p)
+		}
+		return cli.SetPort(p)
+	}
+}
+
+// WithProtocol sets the protocol of the API calls, http or https.
+func WithProtocol(s string) Option {
+	return func(cli *Client) error {
+		return cli.SetProtocol(s)
+	}
+}
+
+// WithCredentials sets the username and password of the API calls.
+func WithCredentials(username, password string) Option {
+	return func(cli *Client) error {
+		if err := cli.SetUsername(username); err != nil {
+			return err
+		}
+		return cli.SetPassword(password)
+	}
+}
+
+// WithCredentialProvider sets the provider of the credentials of the API
+// calls.
+func WithCredentialProvider(p CredentialProvider) Option {
+	return func(cli *Client) error {
+		return cli.SetCredentialProvider(p)
+	}
+}
+
+// WithSecure enforces the validation of the certificate of the device.
+func WithSecure() Option {
+	return func(cli *Client) error {
+		return cli.SetSecure()
+	}
+}
+
+// WithCAFile validates the certificate of the device against the CA
+// certificates in the PEM file.
+func WithCAFile(path string) Option {
+	return func(cli *Client) error {
+		return cli.SetCAFile(path)
+	}
+}
+
+// WithServerName overrides the name the certificate of the device is
+// validated against.
+func WithServerName(s string) Option {
+	return func(cli *Client) error {
+		return cli.SetServerName(s)
+	}
+}
+
+// WithClientCertificate presents the client certificate in the PEM files
+// to the device.
+func WithClientCertificate(certFile, keyFile string) Option {
+	return func(cli *Client) error {
+		return cli.SetClientCertificate(certFile, keyFile)
+	}
+}
+
+// WithTimeout sets the time limit of a single API call.
+func WithTimeout(d time.Duration) Option {
+	return func(cli *Client) error {
+		return cli.SetTimeout(d)
+	}
+}
+
+// WithDialTimeout sets the time limit for connecting to the device.
+func WithDialTimeout(d time.Duration) Option {
+	return func(cli *Client) error {
+		return cli.SetDialTimeout(d)
+	}
+}
+
+// WithProxy sends the API calls through the HTTP or SOCKS5 proxy.
+func WithProxy(rawurl string) Option {
+	return func(cli *Client) error {
+		return cli.SetProxy(rawurl)
+	}
+}
+
+// WithUnixSocket sends the API calls to the local NX-API server over the
+// Unix domain socket at the path. Use it with "localhost" host.
+func WithUnixSocket(path string) Option {
+	return func(cli *Client) error {
+		return cli.SetUnixSocket(path)
+	}
+}
+
+// WithSSH runs the commands over SSH on the port rather than over NX-API,
+// see SetSSHTransport. Put it after WithSecure when hostKey is nil.
+func WithSSH(port int, hostKey ssh.HostKeyCallback) Option {
+	return func(cli *Client) error {
+		return cli.SetSSHTransport(port, hostKey)
+	}
+}
+
+// WithHTTPClient sends the API calls with the provided http.Client.
+func WithHTTPClient(c *http.Client) Option {
+	return func(cli *Client) error {
+		return cli.SetHTTPClient(c)
+	}
+}
+
+// WithRetryPolicy sets the retry policy of show commands. A nil policy
+// disables retries.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(cli *Client) error {
+		return cli.SetRetryPolicy(p)
+	}
+}
+
+// WithMaxInFlight limits the number of concurrent API calls.
+func WithMaxInFlight(n int) Option {
+	return func(cli *Client) error {
+		return cli.SetMaxInFlight(n)
+	}
+}
+
+// WithRateLimit limits the rate of the API calls.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(cli *Client) error {
+		return cli.SetRateLimit(rps, burst)
+	}
+}
+
+// WithMiddleware adds middleware to the chain of the client.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(cli *Client) error {
+		cli.Use(mw...)
+		return nil
+	}
+}
+
+// WithLogger logs each HTTP request of the client, with its commands,
+// status, size and latency, and each retry.
+func WithLogger(l Logger) Option {
+	return func(cli *Client) error {
+		if l == nil {
+			return fmt.Errorf("empty logger")
+		}
+		cli.logger = l
+		cli.Use(loggingMiddleware(l))
+		return nil
+	}
+}
+
+func loggingMiddleware(l Logger) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			start := time.Now()
+			resp, err := next(ctx, req)
+			var status string
+			var size int
+			if resp != nil {
+				status, size = resp.Status, len(resp.Body)
+			}
+			if err != nil {
+				l.Printf("nxapi: %s %q: status: %q, error: %s, took %s", req.Host, req.Commands(), status, err, time.Since(start))
+			} else {
+				l.Printf("nxapi: %s %q: status: %q, %d bytes, took %s", req.Host, req.Commands(), status, size, time.Since(start))
+			}
+			return resp, err
+		}
+	}
+}
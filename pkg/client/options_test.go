@@ -0,0 +1,112 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	testOptions := []struct {
+		host      string
+		opts      []Option
+		protocol  string
+		port      int
+		shouldErr bool
+	}{
+		{host: "nysw01", protocol: "https", port: 443},
+		{host: "nysw01", opts: []Option{WithProtocol("http")}, protocol: "http", port: 80},
+		{host: "nysw01", opts: []Option{WithProtocol("http"), WithPort(8080)}, protocol: "http", port: 8080},
+		{host: "nysw01", opts: []Option{WithPort(8443), WithCredentials("admin", "cisco")}, protocol: "https", port: 8443},
+		{host: "", shouldErr: true},
+		{host: "nysw01", opts: []Option{WithProtocol("http"), WithPort(443)}, shouldErr: true},
+		{host: "nysw01", opts: []Option{WithPort(80)}, shouldErr: true},
+		{host: "nysw01", opts: []Option{WithPort(70000)}, shouldErr: true},
+		{host: "nysw01", opts: []Option{WithProtocol("ftp")}, shouldErr: true},
+		{host: "nysw01", opts: []Option{WithProtocol("http"), WithSecure()}, shouldErr: true},
+		{host: "nysw01", opts: []Option{WithCredentials("admin", "")}, shouldErr: true},
+		{host: "nysw01", opts: []Option{WithTimeout(-time.Second)}, shouldErr: true},
+	}
+	for i, test := range testOptions {
+		cli, err := New(test.host, test.opts...)
+		if err != nil {
+			if !test.shouldErr {
+				t.Errorf("FAIL: Test %d: expected to pass, but failed: %s", i, err)
+			}
+			continue
+		}
+		if test.shouldErr {
+			t.Errorf("FAIL: Test %d: expected to fail, but passed", i)
+			continue
+		}
+		if cli.protocol != test.protocol || cli.port != test.port {
+			t.Errorf("FAIL: Test %d: expected %s:%d, got %s:%d", i, test.protocol, test.port, cli.protocol, cli.port)
+		}
+	}
+}
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestClientLogger(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("<html><head><title>Server internal error</title></head></html>"))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`))
+	}))
+	defer server.Close()
+
+	logger := &testLogger{}
+	port, _ := strconv.Atoi(strings.Split(server.URL, ":")[2])
+	cli, err := New("127.0.0.1",
+		WithProtocol("http"),
+		WithPort(port),
+		WithCredentials("admin", "cisco"),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if _, err := cli.GetGeneric("show clock"); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(logger.lines) != 3 {
+		t.Fatalf("client: expected 3 log lines, got: %q", logger.lines)
+	}
+	for i, s := range []string{`500 Internal Server Error`, `retrying in`, `"200 OK", 45 bytes`} {
+		if !strings.Contains(logger.lines[i], s) {
+			t.Errorf("client: log line %d %q does not contain %q", i, logger.lines[i], s)
+		}
+	}
+}
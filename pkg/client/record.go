@@ -0,0 +1,241 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FixtureName returns the name of the file holding the response to the
+// request, following the naming of the files in assets/requests, e.g.
+// "resp.show.ip.arp.json" for "show ip arp". The commands of a multi-command
+// request are joined with "+". The chunks of a chunked output, but the
+// first one, have the session ID appended, e.g. "resp.show.tech.sid2.json".
+func FixtureName(r *Request) string {
+	return "resp." + fixtureBase(r) + fixtureExt(r)
+}
+
+// fixtureBase returns the name of the fixtures of the request without the
+// prefix and the extension, e.g. "show.ip.arp".
+func fixtureBase(r *Request) string {
+	var parts []string
+	for _, cmd := range r.Commands() {
+		parts = append(parts, strings.Join(strings.Fields(cmd), "."))
+	}
+	name := strings.Join(parts, "+")
+	if r.InsAPI != nil && r.InsAPI.Params.Chunk == "1" && r.InsAPI.Params.ID != "1" {
+		name += ".sid" + r.InsAPI.Params.ID
+	}
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			return c
+		case c == '.' || c == '-' || c == '_' || c == '+':
+			return c
+		}
+		return '_'
+	}, name)
+}
+
+func fixtureExt(r *Request) string {
+	if r.ContentType() == "xml" {
+		return ".xml"
+	}
+	return ".json"
+}
+
+// Record instructs the client to write each request and its raw response
+// to files in dir, see Recorder. Use it to capture the output of a device
+// and replay it with NewReplayTransport.
+func (cli *Client) Record(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", dir)
+	}
+	cli.Use(Recorder(dir))
+	return nil
+}
+
+// Recorder returns Middleware writing the payload of each request and its
+// raw response to a pair of files in dir, named like the fixtures in
+// assets/requests, e.g. "reqt.show.ip.arp.1.json" and
+// "resp.show.ip.arp.1.json" for "show ip arp". Each capture of a command
+// takes the next free sequence number, the existing files are never
+// overwritten.
+func Recorder(dir string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			resp, err := next(ctx, req)
+			if resp == nil {
+				return resp, err
+			}
+			if werr := recordFixture(dir, req, resp.Body); werr != nil && err == nil {
+				err = werr
+			}
+			return resp, err
+		}
+	}
+}
+
+// recordFixture writes the request and the response to the files with the
+// lowest sequence number free for both.
+func recordFixture(dir string, req *Request, resp []byte) error {
+	payload, err := req.Payload()
+	if err != nil {
+		return fmt.Errorf("failed recording request: %s", err)
+	}
+	base, ext := fixtureBase(req), fixtureExt(req)
+	for n := 1; ; n++ {
+		reqFp := filepath.Join(dir, fmt.Sprintf("reqt.%s.%d%s", base, n, ext))
+		respFp := filepath.Join(dir, fmt.Sprintf("resp.%s.%d%s", base, n, ext))
+		if _, err := os.Stat(respFp); err == nil {
+			continue
+		}
+		// The request file is created exclusively, it reserves the
+		// sequence number against concurrent recorders.
+		if err := writeFileExcl(reqFp, payload); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed recording request to %s: %s", reqFp, err)
+		}
+		if err := writeFileExcl(respFp, resp); err != nil {
+			return fmt.Errorf("failed recording response to %s: %s", respFp, err)
+		}
+		return nil
+	}
+}
+
+func writeFileExcl(fp string, b []byte) error {
+	f, err := os.OpenFile(fp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReplayTransport is an http.RoundTripper serving the responses recorded
+// in a directory, e.g. by Client.Record, instead of sending the requests
+// to a device. The captures of a command are served in their sequence,
+// e.g. "resp.show.vlan.1.json" and then "resp.show.vlan.2.json", the last
+// one repeating. A command without numbered captures is served the file
+// named by FixtureName. A request without a recorded response fails with
+// "404 Not Found".
+type ReplayTransport struct {
+	dir string
+
+	mu     sync.Mutex
+	served map[string]int
+}
+
+// NewReplayTransport returns ReplayTransport serving the files in dir. Use
+// it with Client.SetHTTPClient:
+//
+//	cli.SetHTTPClient(&http.Client{Transport: client.NewReplayTransport("assets/requests")})
+func NewReplayTransport(dir string) *ReplayTransport {
+	return &ReplayTransport{dir: dir, served: map[string]int{}}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	r, err := parseRequest(req.Header.Get("Content-Type"), body)
+	if err != nil {
+		return replayResponse(req, http.StatusBadRequest, []byte(err.Error())), nil
+	}
+	fc, err := t.next(r)
+	if err != nil {
+		return replayResponse(req, http.StatusNotFound, []byte(err.Error())), nil
+	}
+	return replayResponse(req, http.StatusOK, fc), nil
+}
+
+// next returns the response to serve to the request.
+func (t *ReplayTransport) next(r *Request) ([]byte, error) {
+	base, ext := fixtureBase(r), fixtureExt(r)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := t.served[base] + 1
+	if fc, err := ioutil.ReadFile(filepath.Join(t.dir, fmt.Sprintf("resp.%s.%d%s", base, n, ext))); err == nil {
+		t.served[base] = n
+		return fc, nil
+	}
+	if n > 1 {
+		return ioutil.ReadFile(filepath.Join(t.dir, fmt.Sprintf("resp.%s.%d%s", base, n-1, ext)))
+	}
+	return ioutil.ReadFile(filepath.Join(t.dir, FixtureName(r)))
+}
+
+func replayResponse(req *http.Request, code int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// parseRequest decodes the payload of an HTTP request sent by the client.
+func parseRequest(contentType string, body []byte) (*Request, error) {
+	r := &Request{}
+	switch contentType {
+	case "application/json-rpc":
+		if err := json.Unmarshal(body, &r.JSONRPC); err != nil {
+			return nil, fmt.Errorf("parsing error: %s", err)
+		}
+	case "application/json":
+		if err := json.Unmarshal(body, &r.InsAPI); err != nil {
+			return nil, fmt.Errorf("parsing error: %s", err)
+		}
+	case "application/xml":
+		r.InsAPI = &InsAPIRequest{}
+		if err := xml.Unmarshal(body, &r.InsAPI.Params); err != nil {
+			return nil, fmt.Errorf("parsing error: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content type: %s", contentType)
+	}
+	return r, nil
+}
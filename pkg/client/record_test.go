@@ -0,0 +1,157 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFixtureName(t *testing.T) {
+	testRequests := []struct {
+		req  *Request
+		name string
+	}{
+		{req: &Request{JSONRPC: NewJSONRPCRequest([]string{"show ip arp"})}, name: "resp.show.ip.arp.json"},
+		{req: &Request{JSONRPC: NewJSONRPCRequest([]string{"show vpc", "show ntp peer-status"})}, name: "resp.show.vpc+show.ntp.peer-status.json"},
+		{req: &Request{InsAPI: NewInsAPICliShowRequest("show interface Ethernet1/1")}, name: "resp.show.interface.Ethernet1_1.json"},
+		{req: &Request{InsAPI: NewInsAPIXMLRequest("show version", "cli_show")}, name: "resp.show.version.xml"},
+		{req: &Request{InsAPI: NewInsAPIChunkRequest("show tech", "cli_show_ascii", "1")}, name: "resp.show.tech.json"},
+		{req: &Request{InsAPI: NewInsAPIChunkRequest("show tech", "cli_show_ascii", "2")}, name: "resp.show.tech.sid2.json"},
+	}
+	for i, test := range testRequests {
+		if name := FixtureName(test.req); name != test.name {
+			t.Errorf("FAIL: Test %d: expected %s, got %s", i, test.name, name)
+		}
+	}
+}
+
+func TestClientRecordReplay(t *testing.T) {
+	dataDir := "../../assets/requests"
+	server := newInsAPITestServer(t)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "nxapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cli := newTestClient(server.URL)
+	if err := cli.Record(dir); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	recorded, err := cli.CliShow(context.Background(), "show vpc")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "resp.show.vpc.1.json")); err != nil {
+		t.Fatalf("client: response not recorded: %s", err)
+	}
+	payload, err := ioutil.ReadFile(filepath.Join(dir, "reqt.show.vpc.1.json"))
+	if err != nil {
+		t.Fatalf("client: request not recorded: %s", err)
+	}
+	if r, err := parseRequest("application/json", payload); err != nil || r.InsAPI.Params.Input != "show vpc" {
+		t.Fatalf("client: unexpected recorded request %s: %v", payload, err)
+	}
+	// Another capture of the command does not overwrite the first one.
+	if _, err := cli.CliShow(context.Background(), "show vpc"); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	for _, name := range []string{"reqt.show.vpc.2.json", "resp.show.vpc.2.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("client: second capture not recorded: %s", err)
+		}
+	}
+
+	replay := NewClient()
+	replay.SetHost("nysw01")
+	replay.SetHTTPClient(&http.Client{Transport: NewReplayTransport(dir)})
+	replayed, err := replay.CliShow(context.Background(), "show vpc")
+	if err != nil {
+		t.Fatalf("replay: %s", err)
+	}
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Fatalf("replay: replayed output differs from the recorded one")
+	}
+	_, err = replay.CliShow(context.Background(), "show clock")
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("replay: expected 404 for a command without a fixture, got: %v", err)
+	}
+
+	// The fixtures of the repository are served as well.
+	replay.SetHTTPClient(&http.Client{Transport: NewReplayTransport(dataDir)})
+	output, err := replay.CliShow(context.Background(), "show vpc")
+	if err != nil {
+		t.Fatalf("replay: %s", err)
+	}
+	var vpc ShowVpcResultBody
+	if err := output[0].Decode(&vpc); err != nil {
+		t.Fatalf("replay: %s", err)
+	}
+	if vpc.VpcDomainID != "100" {
+		t.Fatalf("replay: unexpected output: %#v", vpc)
+	}
+	replay.SetHTTPClient(&http.Client{Transport: NewReplayTransport("testdata/synthetic")})
+	var result ShowVersionResponseResult
+	if err := replay.CliShowXML(context.Background(), "show version", &result); err != nil {
+		t.Fatalf("replay: %s", err)
+	}
+	if result.Body.HostName != "macsec2" {
+		t.Fatalf("replay: unexpected output: %#v", result)
+	}
+}
+
+func TestReplayTransportSequence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nxapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for n, msg := range []string{"", "first", "second"} {
+		name := "resp.show.clock.json"
+		if n > 0 {
+			name = fmt.Sprintf("resp.show.clock.%d.json", n)
+		}
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","result":{"body":{"simple_time":"%s"}},"id":1}`, msg)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replay := NewClient()
+	replay.SetHost("nysw01")
+	replay.SetHTTPClient(&http.Client{Transport: NewReplayTransport(dir)})
+	// The captures are served in their sequence, the last one repeats.
+	for i, exp := range []string{"first", "second", "second"} {
+		output, err := replay.GetGeneric("show clock")
+		if err != nil {
+			t.Fatalf("replay: %s", err)
+		}
+		if !strings.Contains(string(output), `"`+exp+`"`) {
+			t.Fatalf("replay: request %d: expected the %s capture, got %s", i, exp, output)
+		}
+	}
+}
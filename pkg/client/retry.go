@@ -0,0 +1,142 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how the client retries show commands after transient
+// failures, e.g. "500 Server Internal Error" or a connection reset while the
+// NX-API backend restarts. Configuration commands are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a call, including the
+	// first one. A value of 1 or less disables retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts.
+	MaxBackoff time.Duration
+	// Multiplier increases the wait after each retry. Values of 1 or less
+	// keep the wait constant.
+	Multiplier float64
+	// Jitter randomizes the wait by up to the given fraction of it, e.g.
+	// 0.2 for +/- 20%, so that pollers do not retry in lockstep.
+	Jitter float64
+	// Retryable reports whether a failed call may be retried. When nil,
+	// IsTransient is used.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy returns the retry policy of a new client: 3 attempts,
+// with waits starting at 500ms.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// SetRetryPolicy sets the retry policy of show commands. A nil policy
+// disables retries.
+func (cli *Client) SetRetryPolicy(p *RetryPolicy) error {
+	if p != nil {
+		if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+			return fmt.Errorf("invalid retry backoff: %s, %s", p.InitialBackoff, p.MaxBackoff)
+		}
+		if p.Jitter < 0 || p.Jitter > 1 {
+			return fmt.Errorf("invalid retry jitter: %v", p.Jitter)
+		}
+	}
+	cli.retryPolicy = p
+	return nil
+}
+
+// IsTransient reports whether the error is a transient failure of NX-API:
+// an HTTP 500, 502, 503 or 504 response, an unexpected EOF, or a reset or
+// refused connection. Errors of the commands, e.g. ErrInvalidCommand, and
+// context cancellation are not transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case 500, 502, 503, 504:
+			return true
+		}
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// callAPI sends the request, retrying transient failures according to the
+// retry policy of the client.
+func (cli *Client) callAPI(ctx context.Context, r *Request) ([]byte, error) {
+	p := cli.retryPolicy
+	body, err := cli.callAPIOnce(ctx, r)
+	if p == nil {
+		return body, err
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsTransient
+	}
+	backoff := p.InitialBackoff
+	for attempt := 1; attempt < p.MaxAttempts && err != nil && retryable(err); attempt++ {
+		wait := p.jitter(backoff)
+		if cli.logger != nil {
+			cli.logger.Printf("nxapi: %s: attempt %d failed, retrying in %s: %s", cli.host, attempt, wait, err)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		body, err = cli.callAPIOnce(ctx, r)
+		if p.Multiplier > 1 {
+			backoff = time.Duration(float64(backoff) * p.Multiplier)
+		}
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+	return body, err
+}
+
+func (p *RetryPolicy) jitter(d time.Duration) time.Duration {
+	if p.Jitter == 0 || d <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClientRetry(t *testing.T) {
+	var calls, failures int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		calls++
+		if calls <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("<html><head><title>Server internal error</title></head></html>"))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`))
+	}))
+	defer server.Close()
+
+	cli := newTestClient(server.URL)
+	if err := cli.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2, Jitter: 0.2}); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+
+	testFailures := []struct {
+		failures  int
+		calls     int
+		shouldErr bool
+	}{
+		{failures: 0, calls: 1},
+		{failures: 2, calls: 3},
+		{failures: 5, calls: 3, shouldErr: true},
+	}
+	for i, test := range testFailures {
+		calls, failures = 0, test.failures
+		_, err := cli.GetGeneric("show clock")
+		if (err != nil) != test.shouldErr {
+			t.Fatalf("FAIL: Test %d: unexpected error: %v", i, err)
+		}
+		if test.shouldErr && !errors.Is(err, ErrServerInternal) {
+			t.Fatalf("FAIL: Test %d: expected server internal error, got: %v", i, err)
+		}
+		if calls != test.calls {
+			t.Fatalf("FAIL: Test %d: expected %d calls, got %d", i, test.calls, calls)
+		}
+	}
+
+	// Configuration commands are never retried.
+	calls, failures = 0, 1
+	if _, err := cli.Configure([]string{"interface Ethernet1/1", "shutdown"}); !errors.Is(err, ErrServerInternal) {
+		t.Fatalf("client: expected server internal error, got: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("client: configuration retried, %d calls", calls)
+	}
+
+	// Retries are disabled without a policy.
+	cli.SetRetryPolicy(nil)
+	calls, failures = 0, 1
+	if _, err := cli.GetGeneric("show clock"); err == nil || calls != 1 {
+		t.Fatalf("client: expected a single failed call, got %d calls, error: %v", calls, err)
+	}
+
+	// The wait between attempts is bound to the context.
+	cli.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour})
+	calls, failures = 0, 1
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := cli.GetGenericContext(ctx, "show clock"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("client: expected context deadline exceeded, got: %v", err)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	testErrors := []struct {
+		err       error
+		transient bool
+	}{
+		{err: &HTTPError{StatusCode: 500}, transient: true},
+		{err: &HTTPError{StatusCode: 503}, transient: true},
+		{err: &HTTPError{StatusCode: 401}},
+		{err: &RPCError{Code: 400, Message: "Input CLI command error"}},
+		{err: fmt.Errorf("read: %w", syscall.ECONNRESET), transient: true},
+		{err: fmt.Errorf("response: <nil>: %w", io.EOF), transient: true},
+		{err: context.Canceled},
+		{err: nil},
+	}
+	for i, test := range testErrors {
+		if IsTransient(test.err) != test.transient {
+			t.Errorf("FAIL: Test %d: %v, expected transient %t", i, test.err, test.transient)
+		}
+	}
+}
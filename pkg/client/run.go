@@ -0,0 +1,245 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/pschou/go-json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Run sends the show command and decodes the body of its output into a
+// new T, e.g. ShowVpcResultBody:
+//
+//	vpc, err := client.Run[client.ShowVpcResultBody](ctx, cli, "show vpc")
+//
+// When the command is registered with RegisterFunc for T, the body is
+// decoded with the registered function.
+func Run[T any](ctx context.Context, cli *Client, cmd string) (*T, error) {
+	resp, err := cli.callShow(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	body, err := responseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if e := lookup(cmd); e != nil && e.typ == typeOf[T]() {
+		v, err := e.decode(body)
+		if err != nil {
+			return nil, err
+		}
+		return v.(*T), nil
+	}
+	return decodeBody[T](body)
+}
+
+// Decode decodes the body of a captured response into a new T. The
+// response is either a JSON-RPC response, with the body in "result", an
+// NX-OS API response, with the body in "outputs.output", or a single
+// result or output, i.e. the input of the New...ResultFromBytes parsers.
+func Decode[T any](b []byte) (*T, error) {
+	body, err := responseBody(b)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBody[T](body)
+}
+
+// responseBody returns the body of the output of a single command in the
+// response. An error reported for the command is returned as *RPCError.
+func responseBody(b []byte) ([]byte, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	if b[0] == '[' {
+		var resps []json.RawMessage
+		if err := json.Unmarshal(b, &resps); err != nil {
+			return nil, fmt.Errorf("parsing error: %s, server response: %s", err, string(b))
+		}
+		if len(resps) != 1 {
+			return nil, fmt.Errorf("expecting the output of a single command, got %d", len(resps))
+		}
+		b = resps[0]
+	}
+	resp := &struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+		InsAPI *struct {
+			Outputs struct {
+				Output InsAPIOutputs `json:"output"`
+			} `json:"outputs"`
+		} `json:"ins_api"`
+		Body json.RawMessage `json:"body"`
+	}{}
+	if err := json.Unmarshal(b, resp); err != nil {
+		return nil, fmt.Errorf("parsing error: %s, server response: %s", err, string(b))
+	}
+	switch {
+	case resp.Error != nil:
+		return nil, resp.Error
+	case resp.InsAPI != nil:
+		outputs := resp.InsAPI.Outputs.Output
+		if len(outputs) != 1 {
+			return nil, fmt.Errorf("expecting the output of a single command, got %d", len(outputs))
+		}
+		if err := outputs[0].Err(); err != nil {
+			return nil, err
+		}
+		return outputs[0].Body, nil
+	case resp.Result != nil:
+		result := &JSONRPCResponseBody{}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return nil, fmt.Errorf("parsing error: %s, server response: %s", err, string(resp.Result))
+		}
+		return result.Body, nil
+	case resp.Body != nil:
+		return resp.Body, nil
+	}
+	return nil, fmt.Errorf("unsupported response: %s", string(b))
+}
+
+// decodeBody decodes the body into a new T, as the parsers of the package
+// do.
+func decodeBody[T any](body []byte) (*T, error) {
+	v := new(T)
+	if err := decodeResult(body, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+type registryEntry struct {
+	typ    reflect.Type
+	decode func(body []byte) (interface{}, error)
+}
+
+var registry = struct {
+	sync.RWMutex
+	commands map[string]*registryEntry
+}{commands: map[string]*registryEntry{}}
+
+// normalizeCommand collapses the whitespace of the command.
+func normalizeCommand(cmd string) string {
+	return strings.Join(strings.Fields(cmd), " ")
+}
+
+func lookup(cmd string) *registryEntry {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.commands[normalizeCommand(cmd)]
+}
+
+// Register registers T as the type of the body of the show command, for
+// RunRegistered. Registering a command again replaces its type.
+func Register[T any](cmd string) {
+	RegisterFunc[T](cmd, decodeBody[T])
+}
+
+// RegisterFunc is like Register, but the body is decoded with the
+// function, e.g. to post-process the output.
+func RegisterFunc[T any](cmd string, decode func(body []byte) (*T, error)) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.commands[normalizeCommand(cmd)] = &registryEntry{
+		typ: typeOf[T](),
+		decode: func(body []byte) (interface{}, error) {
+			return decode(body)
+		},
+	}
+}
+
+// Registered returns the registered show commands, sorted.
+func Registered() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	var cmds []string
+	for cmd := range registry.commands {
+		cmds = append(cmds, cmd)
+	}
+	sort.Strings(cmds)
+	return cmds
+}
+
+// ResultType returns the type of the body of the registered command.
+func ResultType(cmd string) (reflect.Type, bool) {
+	e := lookup(cmd)
+	if e == nil {
+		return nil, false
+	}
+	return e.typ, true
+}
+
+// RunRegistered sends the registered show command and returns its body
+// decoded into a pointer to the registered type, e.g. *ShowVpcResultBody
+// for "show vpc".
+func RunRegistered(ctx context.Context, cli *Client, cmd string) (interface{}, error) {
+	e := lookup(cmd)
+	if e == nil {
+		return nil, fmt.Errorf("unregistered command: %s", cmd)
+	}
+	resp, err := cli.callShow(ctx, normalizeCommand(cmd))
+	if err != nil {
+		return nil, err
+	}
+	body, err := responseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	return e.decode(body)
+}
+
+func init() {
+	Register[ShowBgpSessionsResultBody]("show bgp sessions")
+	Register[ShowCdpNeighborsResultBody]("show cdp neighbors")
+	RegisterFunc("show environment", func(body []byte) (*ShowEnvironmentResultBody, error) {
+		// The environment parser trims the padding of the values.
+		result, err := NewShowEnvironmentResultFromBytes([]byte(`{"body":` + string(body) + `}`))
+		if err != nil {
+			return nil, err
+		}
+		return &result.Body, nil
+	})
+	Register[ShowHsrpResultBody]("show hsrp")
+	Register[ShowInterfaceBriefResponse]("show interface brief")
+	Register[ShowInterfaceCountersErrorsResultBody]("show interface counters errors")
+	Register[ShowInterfaceQuickResultBody]("show interface")
+	Register[InterfaceStatusResultBody]("show interface status")
+	Register[ShowInterfaceTransceiverDetailsResultBody]("show interface transceiver details")
+	Register[ShowIpArpResultBody]("show ip arp")
+	Register[ShowIpArpDetailVrfAllResultBody]("show ip arp detail vrf all")
+	Register[ShowIpEigrpNeighborsVrfAllResultBody]("show ip eigrp neighbors vrf all")
+	Register[ShowIpRouteResultBody]("show ip route")
+	Register[ShowIsisAdjDetailResultBody]("show isis adjacency detail")
+	Register[ShowMacAddressTableResultBody]("show mac address-table")
+	Register[ShowModuleResultBody]("show module")
+	Register[ShowNtpPeerStatusResultBody]("show ntp peer-status")
+	Register[ShowPortChannelSummaryResultBody]("show port-channel summary")
+	Register[ShowPortSecurityAddressResultBody]("show port-security address")
+	Register[ShowProcessesCpuResultBody]("show processes cpu")
+	Register[ShowSystemResourcesResultBody]("show system resources")
+	Register[ShowVersionResultBody]("show version")
+	Register[ShowVpcResultBody]("show vpc")
+}
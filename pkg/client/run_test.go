@@ -0,0 +1,160 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	dataDir := "../../assets/requests"
+	testcases := []struct {
+		name     string
+		input    string
+		hostname string
+		err      error
+	}{
+		{name: "JSON-RPC response", input: "resp.show.version.3.json", hostname: "switch"},
+		{name: "NX-OS API response", input: "resp.show.version.json", hostname: "macsec2"},
+		{name: "single output", input: "resp.result.show.version.json", hostname: "macsec2"},
+		{name: "JSON-RPC error", input: "resp.error.200.invalid.request.txt", err: ErrInvalidRequest},
+	}
+	for _, tc := range testcases {
+		fc, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", dataDir, tc.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := Decode[ShowVersionResultBody](fc)
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("%s: expected %v, got %v", tc.name, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		if v.HostName != tc.hostname {
+			t.Fatalf("%s: expected host name %q, got %q", tc.name, tc.hostname, v.HostName)
+		}
+	}
+
+	output := `{"ins_api":{"outputs":{"output":{"input":"show foo","code":"400","msg":"Input CLI command error","clierror":"% Invalid command"}}}}`
+	if _, err := Decode[ShowVersionResultBody]([]byte(output)); !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("expected %v, got %v", ErrInvalidCommand, err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dataDir := "../../assets/requests"
+	fixtures := map[string]string{
+		"show vpc":         "resp.show.vpc.json",
+		"show environment": "resp.show.environment.json",
+		"show clock":       "resp.show.clock.json",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var j []*JSONRPCRequest
+		if err := json.NewDecoder(req.Body).Decode(&j); err != nil || len(j) != 1 {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		fp, found := fixtures[j[0].Params.Command]
+		if !found {
+			w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":{"msg":"Request contains invalid CLI command"}},"id":1}`))
+			return
+		}
+		fc, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", dataDir, fp))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if bytes.Contains(fc, []byte(`"ins_api"`)) {
+			resp, err := NewInsAPIResponseFromBytes(fc)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			fc = []byte(fmt.Sprintf(`{"jsonrpc":"2.0","result":{"body":%s},"id":1}`, resp.InsAPI.Outputs.Output[0].Body))
+		}
+		w.Write(fc)
+	}))
+	defer server.Close()
+	cli := newTestClient(server.URL)
+	ctx := context.Background()
+
+	vpc, err := Run[ShowVpcResultBody](ctx, cli, "show vpc")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if vpc.VpcDomainID != "100" {
+		t.Fatalf("client: expected vpc domain 100, got %q", vpc.VpcDomainID)
+	}
+
+	v, err := RunRegistered(ctx, cli, "show  vpc")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if _, ok := v.(*ShowVpcResultBody); !ok {
+		t.Fatalf("client: expected *ShowVpcResultBody, got %T", v)
+	}
+
+	// The environment is decoded with its registered function, which trims
+	// the values.
+	env, err := Run[ShowEnvironmentResultBody](ctx, cli, "show environment")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	for _, table := range env.Powersup.TableModPowInfo {
+		for _, row := range table.RowModPowInfo {
+			if row.ModModel != strings.TrimSpace(row.ModModel) {
+				t.Fatalf("client: expected trimmed module model, got %q", row.ModModel)
+			}
+		}
+	}
+
+	// A new command only needs its type.
+	type clock struct {
+		SimpleTime string `json:"simple_time"`
+		TimeSource string `json:"time_source"`
+	}
+	if _, err := RunRegistered(ctx, cli, "show clock"); err == nil {
+		t.Fatalf("client: expected unregistered command to fail")
+	}
+	Register[clock]("show clock")
+	if typ, found := ResultType("show clock"); !found || typ != reflect.TypeOf(clock{}) {
+		t.Fatalf("client: unexpected result type of show clock: %v", typ)
+	}
+	v, err = RunRegistered(ctx, cli, "show clock")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if c := v.(*clock); c.TimeSource != "NTP" {
+		t.Fatalf("client: expected time source NTP, got %q", c.TimeSource)
+	}
+
+	if _, err := Run[ShowVpcResultBody](ctx, cli, "show foo"); !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("client: expected %v, got %v", ErrInvalidCommand, err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// SessionCookieName is the name of the cookie NX-API returns after a
+// successful basic authentication.
+const SessionCookieName = "nxapi_auth"
+
+// SetSessionReuse enables or disables the reuse of the NX-API session
+// cookie. When enabled (the default), the client authenticates once and
+// sends the session cookie with the subsequent calls instead of the
+// credentials. This avoids an AAA round trip, e.g. to a TACACS server, for
+// every call.
+func (cli *Client) SetSessionReuse(enabled bool) error {
+	cli.sessionMu.Lock()
+	defer cli.sessionMu.Unlock()
+	cli.noSession = !enabled
+	cli.session = nil
+	return nil
+}
+
+// ClearSession discards the NX-API session cookie. The next call
+// authenticates with the credentials of the client.
+func (cli *Client) ClearSession() {
+	cli.sessionMu.Lock()
+	defer cli.sessionMu.Unlock()
+	cli.session = nil
+	cli.sessionExpires = time.Time{}
+}
+
+// sessionCookie returns the current session cookie, or nil if there is no
+// valid session.
+func (cli *Client) sessionCookie() *http.Cookie {
+	cli.sessionMu.Lock()
+	defer cli.sessionMu.Unlock()
+	if cli.session == nil {
+		return nil
+	}
+	if !cli.sessionExpires.IsZero() && time.Now().After(cli.sessionExpires) {
+		cli.session = nil
+		return nil
+	}
+	return cli.session
+}
+
+// updateSession captures the session cookie from the response.
+func (cli *Client) updateSession(res *http.Response) {
+	for _, c := range res.Cookies() {
+		if c.Name != SessionCookieName {
+			continue
+		}
+		cli.sessionMu.Lock()
+		defer cli.sessionMu.Unlock()
+		if cli.noSession {
+			return
+		}
+		if c.MaxAge < 0 || c.Value == "" {
+			cli.session = nil
+			return
+		}
+		cli.session = &http.Cookie{Name: c.Name, Value: c.Value}
+		switch {
+		case c.MaxAge > 0:
+			cli.sessionExpires = time.Now().Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			cli.sessionExpires = c.Expires
+		default:
+			cli.sessionExpires = time.Time{}
+		}
+		return
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientSessionReuse(t *testing.T) {
+	var logins, sessionCalls int
+	session := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		if c, err := req.Cookie(SessionCookieName); err == nil {
+			if _, _, ok := req.BasicAuth(); ok {
+				t.Errorf("server: received both credentials and session cookie")
+			}
+			if c.Value != session {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte("<html><head><title>401 Authorization Required</title></head></html>"))
+				return
+			}
+			sessionCalls++
+		} else {
+			user, pass, ok := req.BasicAuth()
+			if !ok || user != "admin" || pass != "cisco" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			logins++
+			session = fmt.Sprintf("session-%d", logins)
+			http.SetCookie(w, &http.Cookie{Name: SessionCookieName, Value: session, MaxAge: 600})
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`))
+	}))
+	defer server.Close()
+
+	cli := newTestClient(server.URL)
+	for i := 0; i < 3; i++ {
+		if _, err := cli.GetGeneric("show clock"); err != nil {
+			t.Fatalf("client: %s", err)
+		}
+	}
+	if logins != 1 || sessionCalls != 2 {
+		t.Fatalf("client: expected 1 login and 2 session calls, got %d and %d", logins, sessionCalls)
+	}
+
+	// The device expired the session, the client re-authenticates.
+	session = "expired"
+	if _, err := cli.GetGeneric("show clock"); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if logins != 2 {
+		t.Fatalf("client: expected re-authentication after session expiry, got %d logins", logins)
+	}
+
+	cli.ClearSession()
+	if _, err := cli.GetGeneric("show clock"); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if logins != 3 {
+		t.Fatalf("client: expected re-authentication after ClearSession, got %d logins", logins)
+	}
+
+	cli.SetSessionReuse(false)
+	for i := 0; i < 2; i++ {
+		if _, err := cli.GetGeneric("show clock"); err != nil {
+			t.Fatalf("client: %s", err)
+		}
+	}
+	if logins != 5 {
+		t.Fatalf("client: expected credentials on every call with session reuse disabled, got %d logins", logins)
+	}
+}
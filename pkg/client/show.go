@@ -0,0 +1,283 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// showResult sends the show command and returns the "result" of its
+// JSON-RPC response, i.e. the input of the New...ResultFromBytes parsers.
+func (cli *Client) showResult(ctx context.Context, cmd string) ([]byte, error) {
+	resp, err := cli.callShow(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	elems, err := splitJSONRPCResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	elem, found := elems[1]
+	if !found {
+		return nil, fmt.Errorf("no response for command: %s", cmd)
+	}
+	if len(elem.response.Result) == 0 {
+		return nil, fmt.Errorf("missing result")
+	}
+	return elem.response.Result, nil
+}
+
+// withVrf appends the VRF to the command, unless it is empty.
+func withVrf(cmd, vrf string) string {
+	if vrf == "" {
+		return cmd
+	}
+	return cmd + " vrf " + vrf
+}
+
+// ShowBgpSessions returns the BGP sessions ("show bgp sessions").
+func (cli *Client) ShowBgpSessions(ctx context.Context) (*ShowBgpSessionsResponseResult, error) {
+	result, err := cli.showResult(ctx, "show bgp sessions")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowBgpSessionsResultFromBytes(result)
+}
+
+// ShowCdpNeighbors returns the CDP neighbors ("show cdp neighbors").
+func (cli *Client) ShowCdpNeighbors(ctx context.Context) (*ShowCdpNeighborsResponseResult, error) {
+	result, err := cli.showResult(ctx, "show cdp neighbors")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowCdpNeighborsResultFromBytes(result)
+}
+
+// ShowEnvironment returns the fans, power supplies and sensors ("show
+// environment").
+func (cli *Client) ShowEnvironment(ctx context.Context) (*ShowEnvironmentResponseResult, error) {
+	result, err := cli.showResult(ctx, "show environment")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowEnvironmentResultFromBytes(result)
+}
+
+// ShowHsrp returns the HSRP groups ("show hsrp").
+func (cli *Client) ShowHsrp(ctx context.Context) (*ShowHsrpResponseResult, error) {
+	result, err := cli.showResult(ctx, "show hsrp")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowHsrpResultFromBytes(result)
+}
+
+// ShowInterfaceBrief returns the brief status of the interfaces ("show
+// interface brief").
+func (cli *Client) ShowInterfaceBrief(ctx context.Context) (*ShowInterfaceBriefResponse, error) {
+	result, err := cli.showResult(ctx, "show interface brief")
+	if err != nil {
+		return nil, err
+	}
+	// The parser of "show interface brief" takes the body of the result.
+	body := &JSONRPCResponseBody{}
+	if err := json.Unmarshal(result, body); err != nil {
+		return nil, fmt.Errorf("parsing error: %s", err)
+	}
+	return NewShowInterfaceBriefFromBytes(body.Body)
+}
+
+// ShowInterfaceCountersErrors returns the error counters of the interfaces
+// ("show interface counters errors").
+func (cli *Client) ShowInterfaceCountersErrors(ctx context.Context) (*ShowInterfaceCountersErrorsResponseResult, error) {
+	result, err := cli.showResult(ctx, "show interface counters errors")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowInterfaceCountersErrorsResultFromBytes(result)
+}
+
+// ShowInterfaceQuick returns the interfaces ("show interface"), decoded
+// without the post-processing of GetInterfaces.
+func (cli *Client) ShowInterfaceQuick(ctx context.Context) (*ShowInterfaceQuickResponseResult, error) {
+	result, err := cli.showResult(ctx, "show interface")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowInterfaceQuickResultFromBytes(result)
+}
+
+// ShowInterfaceStatus returns the status of the interfaces ("show interface
+// status").
+func (cli *Client) ShowInterfaceStatus(ctx context.Context) (*InterfaceStatusResponseResult, error) {
+	result, err := cli.showResult(ctx, "show interface status")
+	if err != nil {
+		return nil, err
+	}
+	return NewInterfaceStatusResultFromBytes(result)
+}
+
+// ShowInterfaceTransceiverDetails returns the transceivers of the
+// interfaces ("show interface transceiver details").
+func (cli *Client) ShowInterfaceTransceiverDetails(ctx context.Context) (*ShowInterfaceTransceiverDetailsResponseResult, error) {
+	result, err := cli.showResult(ctx, "show interface transceiver details")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowInterfaceTransceiverDetailsResultFromBytes(result)
+}
+
+// ShowIpArp returns the ARP table of the VRF ("show ip arp vrf <vrf>"). An
+// empty vrf selects the default VRF.
+func (cli *Client) ShowIpArp(ctx context.Context, vrf string) (*ShowIpArpResponseResult, error) {
+	result, err := cli.showResult(ctx, withVrf("show ip arp", vrf))
+	if err != nil {
+		return nil, err
+	}
+	return NewShowIpArpResultFromBytes(result)
+}
+
+// ShowIpArpDetailVrfAll returns the ARP tables of all VRFs ("show ip arp
+// detail vrf all").
+func (cli *Client) ShowIpArpDetailVrfAll(ctx context.Context) (*ShowIpArpDetailVrfAllResponseResult, error) {
+	result, err := cli.showResult(ctx, "show ip arp detail vrf all")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowIpArpDetailVrfAllResultFromBytes(result)
+}
+
+// ShowIpEigrpNeighborsVrfAll returns the EIGRP neighbors in all VRFs ("show
+// ip eigrp neighbors vrf all").
+func (cli *Client) ShowIpEigrpNeighborsVrfAll(ctx context.Context) (*ShowIpEigrpNeighborsVrfAllResponseResult, error) {
+	result, err := cli.showResult(ctx, "show ip eigrp neighbors vrf all")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowIpEigrpNeighborsVrfAllResultFromBytes(result)
+}
+
+// ShowIpRoute returns the routing table of the VRF ("show ip route vrf
+// <vrf>"). An empty vrf selects the default VRF.
+func (cli *Client) ShowIpRoute(ctx context.Context, vrf string) (*ShowIpRouteResponseResult, error) {
+	result, err := cli.showResult(ctx, withVrf("show ip route", vrf))
+	if err != nil {
+		return nil, err
+	}
+	return NewShowIpRouteResultFromBytes(result)
+}
+
+// ShowIsisAdjDetail returns the IS-IS adjacencies of the instance ("show
+// isis <tag> adjacency detail"). An empty tag selects all instances.
+func (cli *Client) ShowIsisAdjDetail(ctx context.Context, tag string) (*ShowIsisAdjDetailResponseResult, error) {
+	cmd := "show isis adjacency detail"
+	if tag != "" {
+		cmd = "show isis " + tag + " adjacency detail"
+	}
+	result, err := cli.showResult(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return NewShowIsisAdjDetailResultFromBytes(result)
+}
+
+// ShowMacAddressTable returns the MAC address table ("show mac
+// address-table").
+func (cli *Client) ShowMacAddressTable(ctx context.Context) (*ShowMacAddressTableResponseResult, error) {
+	result, err := cli.showResult(ctx, "show mac address-table")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowMacAddressTableResultFromBytes(result)
+}
+
+// ShowModule returns the modules of the chassis ("show module").
+func (cli *Client) ShowModule(ctx context.Context) (*ShowModuleResponseResult, error) {
+	result, err := cli.showResult(ctx, "show module")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowModuleResultFromBytes(result)
+}
+
+// ShowNtpPeerStatus returns the status of the NTP peers ("show ntp
+// peer-status").
+func (cli *Client) ShowNtpPeerStatus(ctx context.Context) (*ShowNtpPeerStatusResponseResult, error) {
+	result, err := cli.showResult(ctx, "show ntp peer-status")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowNtpPeerStatusResultFromBytes(result)
+}
+
+// ShowPortChannelSummary returns the port-channels and their members
+// ("show port-channel summary").
+func (cli *Client) ShowPortChannelSummary(ctx context.Context) (*ShowPortChannelSummaryResponseResult, error) {
+	result, err := cli.showResult(ctx, "show port-channel summary")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowPortChannelSummaryResultFromBytes(result)
+}
+
+// ShowPortSecurityAddress returns the secure MAC addresses ("show
+// port-security address").
+func (cli *Client) ShowPortSecurityAddress(ctx context.Context) (*ShowPortSecurityAddressResponseResult, error) {
+	result, err := cli.showResult(ctx, "show port-security address")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowPortSecurityAddressResultFromBytes(result)
+}
+
+// ShowProcessesCpu returns the CPU usage of the processes ("show processes
+// cpu").
+func (cli *Client) ShowProcessesCpu(ctx context.Context) (*ShowProcessesCpuResponseResult, error) {
+	result, err := cli.showResult(ctx, "show processes cpu")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowProcessesCpuResultFromBytes(result)
+}
+
+// ShowSystemResources returns the CPU and memory usage ("show system
+// resources").
+func (cli *Client) ShowSystemResources(ctx context.Context) (*ShowSystemResourcesResponseResult, error) {
+	result, err := cli.showResult(ctx, "show system resources")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowSystemResourcesResultFromBytes(result)
+}
+
+// ShowVersion returns the software and hardware versions ("show version").
+func (cli *Client) ShowVersion(ctx context.Context) (*ShowVersionResponseResult, error) {
+	result, err := cli.showResult(ctx, "show version")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowVersionResultFromBytes(result)
+}
+
+// ShowVpc returns the status of the vPC domain ("show vpc").
+func (cli *Client) ShowVpc(ctx context.Context) (*ShowVpcResponseResult, error) {
+	result, err := cli.showResult(ctx, "show vpc")
+	if err != nil {
+		return nil, err
+	}
+	return NewShowVpcResultFromBytes(result)
+}
@@ -0,0 +1,523 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientShow(t *testing.T) {
+	dataDir := "../../assets/requests"
+	testcases := []struct {
+		cmd     string
+		fixture string
+		call    func(cli *Client, ctx context.Context) (interface{}, error)
+		want    func(b []byte) (interface{}, error)
+	}{
+		{
+			cmd:     "show bgp sessions",
+			fixture: "resp.show.bgp.sessions.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowBgpSessions(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowBgpSessionsFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show cdp neighbors",
+			fixture: "resp.show.cdp.neighbors.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowCdpNeighbors(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowCdpNeighborsFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show environment",
+			fixture: "resp.show.environment.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowEnvironment(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowEnvironmentFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show hsrp",
+			fixture: "resp.show.hsrp.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowHsrp(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowHsrpFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show interface brief",
+			fixture: "resp.show.interface.brief.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				return cli.ShowInterfaceBrief(ctx)
+			},
+			want: func(b []byte) (interface{}, error) {
+				return NewShowInterfaceBriefFromBytes(b)
+			},
+		},
+		{
+			cmd:     "show interface counters errors",
+			fixture: "resp.show.interface.counters.errors.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowInterfaceCountersErrors(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowInterfaceCountersErrorsFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show interface",
+			fixture: "resp.show.interface.quick.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowInterfaceQuick(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowInterfaceQuickFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show interface status",
+			fixture: "resp.show.interface.status.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowInterfaceStatus(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewInterfaceStatusFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show interface transceiver details",
+			fixture: "resp.show.interface.transceiver.details.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowInterfaceTransceiverDetails(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowInterfaceTransceiverDetailsFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show ip arp vrf management",
+			fixture: "resp.show.ip.arp.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowIpArp(ctx, "management")
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowIpArpFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show ip arp detail vrf all",
+			fixture: "resp.show.ip.arp.detail.vrf.all.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowIpArpDetailVrfAll(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowIpArpDetailVrfAllFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show ip eigrp neighbors vrf all",
+			fixture: "resp.show.ip.eigrp.neighbors.vrf.all.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowIpEigrpNeighborsVrfAll(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowIpEigrpNeighborsVrfAllFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show ip route",
+			fixture: "resp.show.ip.route.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowIpRoute(ctx, "")
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowIpRouteFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show isis 2 adjacency detail",
+			fixture: "resp.show.isis.2.adj.det.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowIsisAdjDetail(ctx, "2")
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowIsisAdjDetailFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show module",
+			fixture: "resp.show.module.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowModule(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowModuleFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show ntp peer-status",
+			fixture: "resp.show.ntp.peer-status.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowNtpPeerStatus(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowNtpPeerStatusFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show port-security address",
+			fixture: "resp.show.port-security.address.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowPortSecurityAddress(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowPortSecurityAddressFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show system resources",
+			fixture: "resp.show.system.resources.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowSystemResources(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowSystemResourcesFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show version",
+			fixture: "resp.show.version.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowVersion(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowVersionFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+		{
+			cmd:     "show mac address-table",
+			fixture: "resp.show.mac.address.table.1.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowMacAddressTable(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				var resp JSONRPCResponse
+				if err := json.Unmarshal(b, &resp); err != nil {
+					return nil, err
+				}
+				r, err := NewShowMacAddressTableResultFromBytes(resp.Result)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+		},
+		{
+			cmd:     "show port-channel summary",
+			fixture: "resp.show.port.channel.summary.1.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowPortChannelSummary(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				var resp JSONRPCResponse
+				if err := json.Unmarshal(b, &resp); err != nil {
+					return nil, err
+				}
+				r, err := NewShowPortChannelSummaryResultFromBytes(resp.Result)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+		},
+		{
+			cmd:     "show processes cpu",
+			fixture: "resp.show.processes.cpu.1.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowProcessesCpu(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				var resp JSONRPCResponse
+				if err := json.Unmarshal(b, &resp); err != nil {
+					return nil, err
+				}
+				r, err := NewShowProcessesCpuResultFromBytes(resp.Result)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+		},
+		{
+			cmd:     "show vpc",
+			fixture: "resp.show.vpc.json",
+			call: func(cli *Client, ctx context.Context) (interface{}, error) {
+				r, err := cli.ShowVpc(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return r.Body, nil
+			},
+			want: func(b []byte) (interface{}, error) {
+				r, err := NewShowVpcFromBytes(b)
+				if err != nil {
+					return nil, err
+				}
+				return r.InsAPI.Outputs.Output.Body, nil
+			},
+		},
+	}
+
+	fixtures := map[string][]byte{}
+	for _, tc := range testcases {
+		fc, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", dataDir, tc.fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixtures[tc.cmd] = fc
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var j []*JSONRPCRequest
+		if err := json.NewDecoder(req.Body).Decode(&j); err != nil || len(j) != 1 {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		fc, found := fixtures[j[0].Params.Command]
+		if !found {
+			w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":{"msg":"Request contains invalid CLI command"}},"id":1}`))
+			return
+		}
+		// The fixtures are JSON-RPC responses, NX-OS API responses, or
+		// bodies.
+		if bytes.Contains(fc, []byte(`"jsonrpc"`)) {
+			w.Write(fc)
+			return
+		}
+		body := fc
+		if bytes.Contains(fc, []byte(`"ins_api"`)) {
+			resp, err := NewInsAPIResponseFromBytes(fc)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			body = resp.InsAPI.Outputs.Output[0].Body
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","result":{"body":%s},"id":1}`, body)
+	}))
+	defer server.Close()
+
+	cli := newTestClient(server.URL)
+	ctx := context.Background()
+	for _, tc := range testcases {
+		got, err := tc.call(cli, ctx)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.cmd, err)
+		}
+		want, err := tc.want(fixtures[tc.cmd])
+		if err != nil {
+			t.Fatalf("%s: %s", tc.cmd, err)
+		}
+		// The results are compared in JSON, reflect.DeepEqual fails on NaN
+		// values, e.g. the "N/A" power draw of absent modules.
+		gotJSON, err := json.Marshal(got)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.cmd, err)
+		}
+		wantJSON, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.cmd, err)
+		}
+		if !bytes.Equal(gotJSON, wantJSON) {
+			t.Fatalf("%s: result does not match the fixture:\ngot:  %s\nwant: %s", tc.cmd, gotJSON, wantJSON)
+		}
+	}
+
+	if _, err := cli.ShowIpRoute(ctx, "blue"); err == nil {
+		t.Fatalf("show ip route vrf blue: expected an error")
+	}
+}
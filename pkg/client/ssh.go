@@ -0,0 +1,341 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// promptRe matches the prompt of NX-OS CLI at the end of the output, e.g.
+// "nysw01# ".
+var promptRe = regexp.MustCompile(`(?:^|\n)[A-Za-z0-9_.\-()]+[#>] ?$`)
+
+// SetSSHTransport instructs the client to run the commands over an
+// interactive SSH session on the port, e.g. 22, rather than over NX-API,
+// for switches with NX-API disabled. The structured output of show
+// commands is obtained with "| json" and wrapped in JSON-RPC or NX-OS API
+// responses, so that the methods of the client work unchanged. Only show
+// commands are supported.
+//
+// The host key of the device is checked with hostKey. When it is nil, the
+// host key is not checked, unless the client is set to be secure.
+func (cli *Client) SetSSHTransport(port int, hostKey ssh.HostKeyCallback) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid ssh port: %d", port)
+	}
+	hostKey, err := cli.sshHostKeyCallback(hostKey)
+	if err != nil {
+		return err
+	}
+	if cli.ssh != nil {
+		cli.ssh.close()
+	}
+	cli.ssh = &sshTransport{cli: cli, port: port, hostKey: hostKey}
+	return nil
+}
+
+// sshHostKeyCallback returns hostKey, or a callback accepting any host key
+// when it is nil and the client is not secure.
+func (cli *Client) sshHostKeyCallback(hostKey ssh.HostKeyCallback) (ssh.HostKeyCallback, error) {
+	if hostKey != nil {
+		return hostKey, nil
+	}
+	if cli.secure {
+		return nil, fmt.Errorf("secure client requires a host key callback")
+	}
+	return ssh.InsecureIgnoreHostKey(), nil
+}
+
+// dialSSH opens an SSH connection to the port of the device, authenticating
+// with the credentials of the client.
+func (cli *Client) dialSSH(ctx context.Context, port int, hostKey ssh.HostKeyCallback) (*ssh.Client, error) {
+	creds, err := cli.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config := &ssh.ClientConfig{
+		User: creds.Username,
+		Auth: []ssh.AuthMethod{
+			ssh.Password(creds.Password),
+			ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = creds.Password
+				}
+				return answers, nil
+			}),
+		},
+		HostKeyCallback: hostKey,
+		Timeout:         cli.dialTimeout,
+	}
+	addr := net.JoinHostPort(cli.host, strconv.Itoa(port))
+	dialContext := cli.dialContext
+	if dialContext == nil {
+		dialContext = (&net.Dialer{Timeout: cli.dialTimeout}).DialContext
+	}
+	conn, err := dialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// sshTransport runs the commands of the client in an interactive SSH
+// session, which is kept open between the calls.
+type sshTransport struct {
+	cli     *Client
+	port    int
+	hostKey ssh.HostKeyCallback
+
+	mu      sync.Mutex
+	client  *ssh.Client
+	session *ssh.Session
+	stdin   io.Writer
+	stdout  io.Reader
+}
+
+// roundTrip is the RoundTripFunc of the SSH transport.
+func (t *sshTransport) roundTrip(ctx context.Context, r *Request) (*Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	var err error
+	if r.InsAPI != nil {
+		body, err = t.insAPI(ctx, r.InsAPI)
+	} else {
+		body, err = t.jsonRPC(ctx, r.JSONRPC)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: 200, Status: "200 OK", Body: body}, nil
+}
+
+func (t *sshTransport) jsonRPC(ctx context.Context, reqs []*JSONRPCRequest) ([]byte, error) {
+	var resps []*JSONRPCResponse
+	for _, req := range reqs {
+		resp := &JSONRPCResponse{Version: "2.0", ID: req.ID}
+		body, cliErr, err := t.show(ctx, req.Params.Command, true)
+		if err != nil {
+			return nil, err
+		}
+		if cliErr != "" {
+			resp.Error = &JSONRPCResponseError{
+				Code:    -32602,
+				Message: "Invalid params",
+				Data:    JSONRPCResponseErrorData{Msg: cliErr},
+			}
+		} else {
+			resp.Result = json.RawMessage(`{"body":` + string(body) + `}`)
+		}
+		resps = append(resps, resp)
+	}
+	if len(resps) == 1 {
+		return json.Marshal(resps[0])
+	}
+	return json.Marshal(resps)
+}
+
+func (t *sshTransport) insAPI(ctx context.Context, req *InsAPIRequest) ([]byte, error) {
+	if req.Params.Format == OutputFormatXML {
+		return nil, fmt.Errorf("ssh transport: unsupported output format: %s", req.Params.Format)
+	}
+	structured := strings.HasPrefix(req.Params.Type, "cli_show") && req.Params.Type != "cli_show_ascii"
+	resp := &InsAPIResponse{}
+	resp.InsAPI.Type = req.Params.Type
+	resp.InsAPI.Version = req.Params.Version
+	resp.InsAPI.Sid = "eoc"
+	for _, cmd := range strings.Split(req.Params.Input, " ;") {
+		body, cliErr, err := t.show(ctx, cmd, structured)
+		if err != nil {
+			return nil, err
+		}
+		output := &InsAPIOutput{Input: cmd, Code: "200", Message: "Success", Body: body}
+		if cliErr != "" {
+			output = &InsAPIOutput{Input: cmd, Code: "400", Message: "Input CLI command error", CliError: cliErr}
+		}
+		resp.InsAPI.Outputs.Output = append(resp.InsAPI.Outputs.Output, output)
+	}
+	return json.Marshal(resp)
+}
+
+// show runs the show command and returns its JSON output, or the error
+// reported by the CLI.
+func (t *sshTransport) show(ctx context.Context, cmd string, structured bool) (json.RawMessage, string, error) {
+	cmd = strings.TrimSpace(cmd)
+	if !strings.HasPrefix(cmd, "show ") {
+		return nil, "", fmt.Errorf("ssh transport: unsupported command: %s", cmd)
+	}
+	line := cmd
+	if structured {
+		line += " | json"
+	}
+	out, err := t.run(ctx, line)
+	if err != nil {
+		return nil, "", err
+	}
+	if strings.HasPrefix(out, "%") || strings.HasPrefix(out, "Syntax error") {
+		return nil, out, nil
+	}
+	if !structured {
+		body, err := json.Marshal(out)
+		return body, "", err
+	}
+	if out == "" {
+		return json.RawMessage("{}"), "", nil
+	}
+	if !json.Valid([]byte(out)) {
+		return nil, "", fmt.Errorf("ssh transport: invalid json output of %s: %s", cmd, out)
+	}
+	return json.RawMessage(out), "", nil
+}
+
+// run sends the line to the shell and returns the output, without the echo
+// of the line and the prompt.
+func (t *sshTransport) run(ctx context.Context, line string) (string, error) {
+	if _, err := io.WriteString(t.stdin, line+"\n"); err != nil {
+		t.reset()
+		return "", err
+	}
+	out, err := t.readUntilPrompt(ctx)
+	if err != nil {
+		t.reset()
+		return "", err
+	}
+	out = strings.Replace(out, "\r", "", -1)
+	// Strip the echo of the line, and the prompt.
+	if i := strings.Index(out, line); i >= 0 {
+		out = out[i+len(line):]
+	}
+	if loc := promptRe.FindStringIndex(out); loc != nil {
+		out = out[:loc[0]]
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// readUntilPrompt reads the output of the shell until the prompt.
+func (t *sshTransport) readUntilPrompt(ctx context.Context) (string, error) {
+	done := make(chan struct{})
+	defer close(done)
+	session := t.session
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Unblock the read, the session is reopened on the next call.
+			session.Close()
+		case <-done:
+		}
+	}()
+	var buf bytes.Buffer
+	b := make([]byte, 32*1024)
+	for {
+		n, err := t.stdout.Read(b)
+		buf.Write(b[:n])
+		if promptRe.Match(bytes.Replace(buf.Bytes(), []byte("\r"), nil, -1)) {
+			return buf.String(), nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			return "", fmt.Errorf("ssh transport: %w", err)
+		}
+	}
+}
+
+// connect opens the SSH session, unless it is open already.
+func (t *sshTransport) connect(ctx context.Context) error {
+	if t.session != nil {
+		return nil
+	}
+	client, err := t.cli.dialSSH(ctx, t.port, t.hostKey)
+	if err != nil {
+		return fmt.Errorf("ssh transport: %w", err)
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("ssh transport: %w", err)
+	}
+	t.client, t.session = client, session
+	if err := t.openShell(ctx); err != nil {
+		t.reset()
+		return err
+	}
+	return nil
+}
+
+func (t *sshTransport) openShell(ctx context.Context) error {
+	var err error
+	if t.stdin, err = t.session.StdinPipe(); err != nil {
+		return err
+	}
+	if t.stdout, err = t.session.StdoutPipe(); err != nil {
+		return err
+	}
+	modes := ssh.TerminalModes{ssh.ECHO: 1}
+	if err := t.session.RequestPty("vt100", 0, 511, modes); err != nil {
+		return fmt.Errorf("ssh transport: %w", err)
+	}
+	if err := t.session.Shell(); err != nil {
+		return fmt.Errorf("ssh transport: %w", err)
+	}
+	if _, err := t.readUntilPrompt(ctx); err != nil {
+		return err
+	}
+	// Disable the paging and the wrapping of the output.
+	for _, line := range []string{"terminal length 0", "terminal width 511"} {
+		if _, err := t.run(ctx, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reset closes the session after a failure.
+func (t *sshTransport) reset() {
+	if t.session != nil {
+		t.session.Close()
+	}
+	if t.client != nil {
+		t.client.Close()
+	}
+	t.client, t.session, t.stdin, t.stdout = nil, nil, nil, nil
+}
+
+func (t *sshTransport) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reset()
+}
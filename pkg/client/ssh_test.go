@@ -0,0 +1,294 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// sshTestServer emulates the CLI of NX-OS over SSH. The "| json" output of
+// show commands is the body of the fixtures.
+type sshTestServer struct {
+	t        *testing.T
+	listener net.Listener
+	config   *ssh.ServerConfig
+	outputs  map[string]string
+	// subsystem serves the "subsystem" requests, e.g. netconf.
+	subsystem func(name string, channel ssh.Channel)
+
+	mu       sync.Mutex
+	conns    []net.Conn
+	sessions int
+}
+
+func newSSHTestServer(t *testing.T) *sshTestServer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &sshTestServer{t: t, outputs: map[string]string{}}
+	s.config = &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "admin" && string(pass) == "cisco" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("password rejected for %s", c.User())
+		},
+	}
+	s.config.AddHostKey(signer)
+	for cmd, fp := range map[string]string{
+		"show version":   "resp.show.version.1.json",
+		"show vlan":      "resp.show.vlans.2.json",
+		"show interface": "resp.show.interfaces.4.json",
+	} {
+		s.outputs[cmd+" | json"] = s.fixtureBody(fp)
+	}
+	s.outputs["show clock"] = "12:00:00.000 UTC Fri Oct 16 2026\r\nTime source is NTP"
+	s.outputs["terminal length 0"] = ""
+	s.outputs["terminal width 511"] = ""
+
+	s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.serve()
+	return s
+}
+
+func (s *sshTestServer) fixtureBody(name string) string {
+	fc, err := ioutil.ReadFile("../../assets/requests/" + name)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	resp := &JSONRPCResponse{}
+	body := &JSONRPCResponseBody{}
+	if err := json.Unmarshal(fc, resp); err != nil {
+		s.t.Fatal(err)
+	}
+	if err := json.Unmarshal(resp.Result, body); err != nil {
+		s.t.Fatal(err)
+	}
+	// NX-OS prints the output of "| json" in a single line.
+	var compact strings.Builder
+	if err := json.NewEncoder(&compact).Encode(body.Body); err != nil {
+		s.t.Fatal(err)
+	}
+	return strings.TrimSpace(compact.String())
+}
+
+func (s *sshTestServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// drop closes the connections of the clients.
+func (s *sshTestServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *sshTestServer) close() {
+	s.listener.Close()
+	s.drop()
+}
+
+func (s *sshTestServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *sshTestServer) handle(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				switch req.Type {
+				case "pty-req":
+					req.Reply(true, nil)
+				case "shell":
+					req.Reply(true, nil)
+					s.mu.Lock()
+					s.sessions++
+					s.mu.Unlock()
+					go s.shell(channel)
+				case "subsystem":
+					var payload struct{ Name string }
+					if s.subsystem == nil || ssh.Unmarshal(req.Payload, &payload) != nil {
+						req.Reply(false, nil)
+						continue
+					}
+					req.Reply(true, nil)
+					go s.subsystem(payload.Name, channel)
+				default:
+					req.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
+
+func (s *sshTestServer) shell(channel ssh.Channel) {
+	defer channel.Close()
+	const prompt = "switch# "
+	fmt.Fprintf(channel, "\r\nCisco Nexus Operating System (NX-OS) Software\r\n%s", prompt)
+	r := bufio.NewReader(channel)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		output, found := s.outputs[line]
+		if !found {
+			output = "% Invalid command at '^' marker."
+		}
+		if output != "" {
+			output += "\r\n"
+		}
+		fmt.Fprintf(channel, "%s\r\n%s%s", line, output, prompt)
+	}
+}
+
+func (s *sshTestServer) sessionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions
+}
+
+func TestClientSSHTransport(t *testing.T) {
+	server := newSSHTestServer(t)
+	defer server.close()
+
+	cli, err := New("127.0.0.1",
+		WithProtocol("http"),
+		WithCredentials("admin", "cisco"),
+		WithSSH(server.port(), nil),
+	)
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+
+	sysinfo, err := cli.GetSystemInfo()
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if sysinfo.Hostname != "switch" {
+		t.Fatalf("client: expected hostname %q, got %q", "switch", sysinfo.Hostname)
+	}
+	vlans, err := cli.GetVlans()
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(vlans) == 0 {
+		t.Fatalf("client: no vlans")
+	}
+	ifaces, err := cli.GetInterfaces()
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(ifaces) == 0 {
+		t.Fatalf("client: no interfaces")
+	}
+
+	outputs, err := cli.CliShowASCII(context.Background(), "show clock")
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if text := outputs[0].Text(); !strings.HasPrefix(text, "12:00:00.000 UTC") || !strings.HasSuffix(text, "NTP") {
+		t.Fatalf("client: unexpected show clock output: %q", text)
+	}
+
+	err = cli.Batch().Add("show version", nil).Add("show foo", nil).Do()
+	if !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("client: expected %v, got %v", ErrInvalidCommand, err)
+	}
+	if _, err := cli.Configure([]string{"vlan 1-2"}); err == nil {
+		t.Fatalf("client: expected configuration commands to fail over ssh")
+	}
+	if n := server.sessionCount(); n != 1 {
+		t.Fatalf("client: expected the session to be reused, got %d sessions", n)
+	}
+
+	// The session is reopened after the device drops it.
+	server.drop()
+	if _, err := cli.GetSystemInfo(); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if n := server.sessionCount(); n != 2 {
+		t.Fatalf("client: expected a new session, got %d sessions", n)
+	}
+}
+
+func TestClientSSHTransportAuth(t *testing.T) {
+	server := newSSHTestServer(t)
+	defer server.close()
+
+	cli, err := New("127.0.0.1",
+		WithProtocol("http"),
+		WithCredentials("admin", "wrong"),
+		WithSSH(server.port(), nil),
+	)
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if _, err := cli.GetSystemInfo(); err == nil {
+		t.Fatalf("client: expected authentication to fail")
+	}
+
+	cli = NewClient()
+	cli.SetSecure()
+	if err := cli.SetSSHTransport(22, nil); err == nil {
+		t.Fatalf("client: expected secure client to require a host key callback")
+	}
+}
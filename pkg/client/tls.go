@@ -0,0 +1,84 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// SetCAFile instructs the client to validate the certificate of the device
+// against the CA certificates in the PEM file, rather than the system ones.
+// It implies SetSecure.
+func (cli *Client) SetCAFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return fmt.Errorf("no certificates found in %s", path)
+	}
+	return cli.SetRootCAs(pool)
+}
+
+// SetRootCAs instructs the client to validate the certificate of the device
+// against the provided CA certificates. It implies SetSecure.
+func (cli *Client) SetRootCAs(pool *x509.CertPool) error {
+	if pool == nil {
+		return fmt.Errorf("empty certificate pool")
+	}
+	cli.rootCAs = pool
+	cli.secure = true
+	cli.resetTransport()
+	return nil
+}
+
+// SetServerName overrides the name the certificate of the device is
+// validated against, e.g. when the device is reached by its IP address but
+// its certificate is issued for its DNS name.
+func (cli *Client) SetServerName(s string) error {
+	if s == "" {
+		return fmt.Errorf("empty server name")
+	}
+	cli.serverName = s
+	cli.resetTransport()
+	return nil
+}
+
+// SetClientCertificate instructs the client to present the certificate in
+// the PEM files to the device, for NX-API certificate-based authentication.
+func (cli *Client) SetClientCertificate(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed loading client certificate: %s", err)
+	}
+	cli.certificates = []tls.Certificate{cert}
+	cli.ClearSession()
+	cli.resetTransport()
+	return nil
+}
+
+// tlsConfig returns the TLS configuration of the transport.
+func (cli *Client) tlsConfig() *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: !cli.secure,
+		RootCAs:            cli.rootCAs,
+		ServerName:         cli.serverName,
+		Certificates:       cli.certificates,
+	}
+}
@@ -55,7 +55,7 @@ func (t *transceiverResponseResultBodyTransceiverLaneTable) UnmarshalJSON(b []by
 	case j > 10 || j < 0:
 		// single entry
 		var r transceiverResponseResultBodyTransceiverLaneRow
-		err := json.Unmarshal(b[(i+2):size-1], &r)
+		err := json.Unmarshal(b[(i+1):size-1], &r)
 		if err != nil {
 			return fmt.Errorf("Error unmarshalling transceiverResponseResultBodyTransceiverLaneTable: %s", err)
 		}
@@ -63,7 +63,7 @@ func (t *transceiverResponseResultBodyTransceiverLaneTable) UnmarshalJSON(b []by
 	case j < 10 && j >= 0:
 		// multiple entries
 		var r []transceiverResponseResultBodyTransceiverLaneRow
-		err := json.Unmarshal(b[(i+2):size-1], &r)
+		err := json.Unmarshal(b[(i+1):size-1], &r)
 		if err != nil {
 			return fmt.Errorf("Error unmarshalling transceiverResponseResultBodyTransceiverLaneTable: %s", err)
 		}
@@ -15,6 +15,7 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"testing"
@@ -84,3 +85,31 @@ func TestParseShowTransceiverJsonOutput(t *testing.T) {
 		t.Fatalf("Failed %d tests", testFailed)
 	}
 }
+
+// The lanes are unmarshalled from the pretty-printed output of NX-API as
+// well as from the compact output of "| json" on the CLI.
+func TestUnmarshalTransceiverLaneTable(t *testing.T) {
+	for i, test := range []struct {
+		input string
+		lanes []uint64
+	}{
+		{input: `{"ROW_lane":{"lane_number":1}}`, lanes: []uint64{1}},
+		{input: `{"ROW_lane": {"lane_number": 1}}`, lanes: []uint64{1}},
+		{input: "{\n  \"ROW_lane\": {\n    \"lane_number\": 1\n  }\n}", lanes: []uint64{1}},
+		{input: `{"ROW_lane":[{"lane_number":1},{"lane_number":2}]}`, lanes: []uint64{1, 2}},
+		{input: `{"ROW_lane": [{"lane_number": 1}, {"lane_number": 2}]}`, lanes: []uint64{1, 2}},
+	} {
+		var table transceiverResponseResultBodyTransceiverLaneTable
+		if err := json.Unmarshal([]byte(test.input), &table); err != nil {
+			t.Errorf("FAIL: Test %d: input '%s', error: %s", i, test.input, err)
+			continue
+		}
+		var lanes []uint64
+		for _, row := range table.TransceiverLaneRow {
+			lanes = append(lanes, row.LaneNumber)
+		}
+		if fmt.Sprint(lanes) != fmt.Sprint(test.lanes) {
+			t.Errorf("FAIL: Test %d: input '%s', expected %v, got %v", i, test.input, test.lanes, lanes)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientConnectionReuse(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`))
+	}))
+	server.Config.ConnState = func(c net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	cli := newTestClient(server.URL)
+	for i := 0; i < 5; i++ {
+		if _, err := cli.GetGeneric("show clock"); err != nil {
+			t.Fatalf("client: %s", err)
+		}
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Fatalf("client: expected 1 connection for 5 calls, got %d", n)
+	}
+
+	// Changing transport settings replaces the transport.
+	if err := cli.SetIdleConnTimeout(time.Minute); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if _, err := cli.GetGeneric("show clock"); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if n := atomic.LoadInt32(&conns); n != 2 {
+		t.Fatalf("client: expected 2 connections after transport reset, got %d", n)
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientSetHTTPClient(t *testing.T) {
+	var calls int
+	cli := NewClient()
+	cli.SetHost("nysw01")
+	err := cli.SetHTTPClient(&http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			if req.URL.String() != "https://nysw01:443/ins" {
+				t.Errorf("unexpected url: %s", req.URL)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`)),
+			}, nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	// Transport settings do not replace a user-provided client.
+	cli.SetTimeout(time.Second)
+	if _, err := cli.GetGeneric("show clock"); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if calls != 1 {
+		t.Fatalf("client: expected the custom transport to be used, got %d calls", calls)
+	}
+
+	if err := cli.SetHTTPClient(nil); err == nil {
+		t.Fatalf("client: expected error for nil http client")
+	}
+	if err := cli.SetMaxIdleConns(-1); err == nil {
+		t.Fatalf("client: expected error for negative max idle connections")
+	}
+}
+
+func TestClientSetDialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`))
+	}))
+	defer server.Close()
+
+	var dialed []string
+	cli := newTestClient(server.URL)
+	cli.SetHost("nysw01")
+	err := cli.SetDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		// Dial the test server in place of the unreachable switch.
+		dialed = append(dialed, addr)
+		var d net.Dialer
+		return d.DialContext(ctx, network, server.Listener.Addr().String())
+	})
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if _, err := cli.GetGeneric("show clock"); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(dialed) != 1 || dialed[0] != fmt.Sprintf("nysw01:%d", cli.port) {
+		t.Fatalf("client: unexpected dials: %v", dialed)
+	}
+}
+
+func TestClientProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		proxied = append(proxied, req.URL.String())
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`))
+	}))
+	defer proxy.Close()
+
+	cli := NewClient()
+	cli.SetHost("nysw01")
+	cli.SetProtocol("http")
+	cli.SetPort(80)
+	if err := cli.SetProxy(proxy.URL); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if _, err := cli.GetGeneric("show clock"); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://nysw01:80/ins" {
+		t.Fatalf("client: unexpected proxied requests: %v", proxied)
+	}
+	if err := cli.SetProxy("ftp://bastion"); err == nil {
+		t.Fatalf("client: expected unsupported proxy scheme error")
+	}
+}
+
+func TestClientSOCKS5Proxy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"body":{}},"id":1}`))
+	}))
+	defer server.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	targets := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		target, err := acceptSOCKS5(conn)
+		if err != nil {
+			t.Errorf("proxy: %s", err)
+			return
+		}
+		targets <- target
+		upstream, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			t.Errorf("proxy: %s", err)
+			return
+		}
+		defer upstream.Close()
+		go io.Copy(upstream, conn)
+		io.Copy(conn, upstream)
+	}()
+
+	cli := newTestClient(server.URL)
+	cli.SetHost("nysw01")
+	if err := cli.SetProxy("socks5://" + ln.Addr().String()); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if _, err := cli.GetGeneric("show clock"); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if target := <-targets; target != fmt.Sprintf("nysw01:%d", cli.port) {
+		t.Fatalf("client: unexpected socks5 target: %s", target)
+	}
+}
+
+// acceptSOCKS5 performs the server side of a SOCKS5 handshake without
+// authentication and returns the requested target.
+func acceptSOCKS5(conn net.Conn) (string, error) {
+	buf := make([]byte, 262)
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return "", err
+	}
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		return "", err
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return "", err
+	}
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+		return "", err
+	}
+	var host string
+	switch buf[3] {
+	case 1:
+		if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+			return "", err
+		}
+		host = net.IP(buf[:4]).String()
+	case 3:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return "", err
+		}
+		n := int(buf[0])
+		if _, err := io.ReadFull(conn, buf[:n]); err != nil {
+			return "", err
+		}
+		host = string(buf[:n])
+	default:
+		return "", fmt.Errorf("unsupported address type: %d", buf[3])
+	}
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return "", err
+	}
+	port := int(buf[0])<<8 | int(buf[1])
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
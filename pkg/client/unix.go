@@ -0,0 +1,57 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// DefaultUnixSocket is the path of the Unix domain socket of the local
+// NX-API nginx server, reachable from the guest shell and from native
+// applications on the switch.
+const DefaultUnixSocket = "/tmp/nginx_local/nginx_1_be_nxapi.sock"
+
+// SetUnixSocket instructs the client to send the API calls to the local
+// NX-API server over the Unix domain socket at the path, e.g.
+// DefaultUnixSocket, rather than to a host over the network. On-box calls
+// need no password: they carry a local session cookie for the username of
+// the client, or "admin" when it is not set.
+func (cli *Client) SetUnixSocket(path string) error {
+	if path == "" {
+		return fmt.Errorf("empty unix socket path")
+	}
+	cli.unixSocket = path
+	cli.host = "localhost"
+	cli.protocol = "http"
+	cli.port = 80
+	cli.proxy = nil
+	cli.ClearSession()
+	return cli.SetDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
+	})
+}
+
+// localSessionCookie returns the cookie authenticating on-box calls.
+func (cli *Client) localSessionCookie() *http.Cookie {
+	username := cli.username
+	if username == "" {
+		username = "admin"
+	}
+	return &http.Cookie{Name: SessionCookieName, Value: username + ":local"}
+}
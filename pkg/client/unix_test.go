@@ -0,0 +1,71 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClientUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nxapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "nginx_1_be_nxapi.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		if _, _, ok := req.BasicAuth(); ok {
+			t.Errorf("server: unexpected basic authentication")
+		}
+		c, err := req.Cookie(SessionCookieName)
+		if err != nil || c.Value != "admin:local" || req.URL.Path != "/ins" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("<html><head><title>401 Authorization Required</title></head></html>"))
+			return
+		}
+		fc, err := ioutil.ReadFile("../../assets/requests/resp.show.version.1.json")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(fc)
+	}))
+	server.Listener = ln
+	server.Start()
+	defer server.Close()
+
+	cli, err := New("localhost", WithUnixSocket(socket))
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	sysinfo, err := cli.GetSystemInfo()
+	if err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if sysinfo.Hostname != "switch" {
+		t.Fatalf("client: unexpected hostname: %s", sysinfo.Hostname)
+	}
+}
@@ -52,7 +52,7 @@ func (t *vlanResponseResultBodyVlanBriefTable) UnmarshalJSON(b []byte) error {
 	case j > 10 || j < 0:
 		// single entry
 		var r vlanResponseResultBodyVlanBriefRow
-		err := json.Unmarshal(b[(i+2):size-1], &r)
+		err := json.Unmarshal(b[(i+1):size-1], &r)
 		if err != nil {
 			return fmt.Errorf("Error unmarshalling vlanResponseResultBodyVlanBriefTable: %s", err)
 		}
@@ -60,7 +60,7 @@ func (t *vlanResponseResultBodyVlanBriefTable) UnmarshalJSON(b []byte) error {
 	case j < 10 && j >= 0:
 		// multiple entries
 		var r []vlanResponseResultBodyVlanBriefRow
-		err := json.Unmarshal(b[(i+2):size-1], &r)
+		err := json.Unmarshal(b[(i+1):size-1], &r)
 		if err != nil {
 			return fmt.Errorf("Error unmarshalling vlanResponseResultBodyVlanBriefTable: %s", err)
 		}
@@ -84,7 +84,7 @@ func (t *vlanResponseResultBodyMtuInfoTable) UnmarshalJSON(b []byte) error {
 	case j > 10 || j < 0:
 		// single entry
 		var r vlanResponseResultBodyMtuInfoRow
-		err := json.Unmarshal(b[(i+2):size-1], &r)
+		err := json.Unmarshal(b[(i+1):size-1], &r)
 		if err != nil {
 			return fmt.Errorf("Error unmarshalling vlanResponseResultBodyMtuInfoTable: %s", err)
 		}
@@ -92,7 +92,7 @@ func (t *vlanResponseResultBodyMtuInfoTable) UnmarshalJSON(b []byte) error {
 	case j < 10 && j >= 0:
 		// multiple entries
 		var r []vlanResponseResultBodyMtuInfoRow
-		err := json.Unmarshal(b[(i+2):size-1], &r)
+		err := json.Unmarshal(b[(i+1):size-1], &r)
 		if err != nil {
 			return fmt.Errorf("Error unmarshalling vlanResponseResultBodyMtuInfoTable: %s", err)
 		}
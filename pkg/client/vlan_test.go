@@ -15,6 +15,7 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"testing"
@@ -85,3 +86,54 @@ func TestParseShowVlanJsonOutput(t *testing.T) {
 		t.Fatalf("Failed %d tests", testFailed)
 	}
 }
+
+// The tables are unmarshalled from the pretty-printed output of NX-API as
+// well as from the compact output of "| json" on the CLI.
+func TestUnmarshalVlanTables(t *testing.T) {
+	for i, test := range []struct {
+		input string
+		ids   []string
+	}{
+		{input: `{"ROW_vlanbrief":{"vlanshowbr-vlanid":"1"}}`, ids: []string{"1"}},
+		{input: `{"ROW_vlanbrief": {"vlanshowbr-vlanid": "1"}}`, ids: []string{"1"}},
+		{input: "{\n  \"ROW_vlanbrief\": {\n    \"vlanshowbr-vlanid\": \"1\"\n  }\n}", ids: []string{"1"}},
+		{input: `{"ROW_vlanbrief":[{"vlanshowbr-vlanid":"1"},{"vlanshowbr-vlanid":"2"}]}`, ids: []string{"1", "2"}},
+		{input: `{"ROW_vlanbrief": [{"vlanshowbr-vlanid": "1"}, {"vlanshowbr-vlanid": "2"}]}`, ids: []string{"1", "2"}},
+	} {
+		var table vlanResponseResultBodyVlanBriefTable
+		if err := json.Unmarshal([]byte(test.input), &table); err != nil {
+			t.Errorf("FAIL: Test %d: input '%s', error: %s", i, test.input, err)
+			continue
+		}
+		var ids []string
+		for _, row := range table.VlanBriefRow {
+			ids = append(ids, row.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(test.ids) {
+			t.Errorf("FAIL: Test %d: input '%s', expected %v, got %v", i, test.input, test.ids, ids)
+		}
+	}
+
+	for i, test := range []struct {
+		input string
+		ids   []string
+	}{
+		{input: `{"ROW_mtuinfo":{"vlanshowinfo-vlanid":"1"}}`, ids: []string{"1"}},
+		{input: `{"ROW_mtuinfo": {"vlanshowinfo-vlanid": "1"}}`, ids: []string{"1"}},
+		{input: `{"ROW_mtuinfo":[{"vlanshowinfo-vlanid":"1"},{"vlanshowinfo-vlanid":"2"}]}`, ids: []string{"1", "2"}},
+		{input: `{"ROW_mtuinfo": [{"vlanshowinfo-vlanid": "1"}, {"vlanshowinfo-vlanid": "2"}]}`, ids: []string{"1", "2"}},
+	} {
+		var table vlanResponseResultBodyMtuInfoTable
+		if err := json.Unmarshal([]byte(test.input), &table); err != nil {
+			t.Errorf("FAIL: Test %d: input '%s', error: %s", i, test.input, err)
+			continue
+		}
+		var ids []string
+		for _, row := range table.MtuInfoRow {
+			ids = append(ids, row.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(test.ids) {
+			t.Errorf("FAIL: Test %d: input '%s', expected %v, got %v", i, test.input, test.ids, ids)
+		}
+	}
+}
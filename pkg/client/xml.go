@@ -0,0 +1,116 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
+// The output formats of NX-OS API requests.
+const (
+	OutputFormatJSON = "json"
+	OutputFormatXML  = "xml"
+)
+
+// NewInsAPIXMLRequest returns an instance of InsAPIRequest based on the
+// provided input and request type, with the output in XML format.
+func NewInsAPIXMLRequest(s, t string) *InsAPIRequest {
+	r := NewInsAPIRequest(s, t)
+	r.Params.Format = OutputFormatXML
+	return r
+}
+
+// MarshalXML encodes the request with "ins_api" root element, as NX-API
+// expects it.
+func (r *InsAPIRequest) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(r.Params, xml.StartElement{Name: xml.Name{Local: "ins_api"}})
+}
+
+type insAPIXMLResponse struct {
+	XMLName xml.Name `xml:"ins_api"`
+	Type    string   `xml:"type"`
+	Version string   `xml:"version"`
+	Sid     string   `xml:"sid"`
+	Outputs struct {
+		Output []insAPIXMLOutput `xml:"output"`
+	} `xml:"outputs"`
+}
+
+type insAPIXMLOutput struct {
+	Input    string `xml:"input"`
+	Code     string `xml:"code"`
+	Message  string `xml:"msg"`
+	CliError string `xml:"clierror"`
+	Inner    []byte `xml:",innerxml"`
+}
+
+// DecodeXML decodes an NX-OS API response in XML format into target, which
+// is a pointer to one of the *Response types, e.g. *ShowVpcResponse.
+func DecodeXML(s []byte, target interface{}) error {
+	// The responses hold the envelope in their "ins_api" field, rather than
+	// being the root element, hence the wrapper.
+	var doc bytes.Buffer
+	doc.WriteString("<nxapi>")
+	doc.Write(stripXMLDeclaration(s))
+	doc.WriteString("</nxapi>")
+	if err := xml.Unmarshal(doc.Bytes(), target); err != nil {
+		return fmt.Errorf("parsing error: %s", err)
+	}
+	return nil
+}
+
+// CliShowXML runs the show command with the output in XML format and decodes
+// the output into target, which is a pointer to one of the *ResponseResult
+// types, e.g. *ShowVpcResponseResult. Some NX-OS releases return more
+// complete data in XML than in JSON.
+func (cli *Client) CliShowXML(ctx context.Context, cmd string, target interface{}) error {
+	b, err := cli.callAPI(ctx, &Request{InsAPI: NewInsAPIXMLRequest(cmd, "cli_show")})
+	if err != nil {
+		return err
+	}
+	resp := &insAPIXMLResponse{}
+	if err := xml.Unmarshal(b, resp); err != nil {
+		return fmt.Errorf("parsing error: %s, server response: %s", err, string(b[:]))
+	}
+	if len(resp.Outputs.Output) == 0 {
+		return fmt.Errorf("no output, server response: %s", string(b[:]))
+	}
+	output := resp.Outputs.Output[0]
+	e := &insAPIErrorOutput{Code: output.Code, Message: output.Message, CliError: output.CliError}
+	if err := e.err(); err != nil {
+		return err
+	}
+	var doc bytes.Buffer
+	doc.WriteString("<output>")
+	doc.Write(output.Inner)
+	doc.WriteString("</output>")
+	if err := xml.Unmarshal(doc.Bytes(), target); err != nil {
+		return fmt.Errorf("parsing error: %s", err)
+	}
+	return nil
+}
+
+func stripXMLDeclaration(s []byte) []byte {
+	s = bytes.TrimSpace(s)
+	if bytes.HasPrefix(s, []byte("<?xml")) {
+		if i := bytes.Index(s, []byte("?>")); i >= 0 {
+			return s[i+2:]
+		}
+	}
+	return s
+}
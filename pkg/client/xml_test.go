@@ -0,0 +1,95 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeXML(t *testing.T) {
+	dataDir := "../../assets/requests"
+	jsonContent, err := ioutil.ReadFile(fmt.Sprintf("%s/resp.show.version.json", dataDir))
+	if err != nil {
+		t.Fatalf("failed reading data from %s: %s", dataDir, err)
+	}
+	xmlContent, err := ioutil.ReadFile("testdata/synthetic/resp.show.version.xml")
+	if err != nil {
+		t.Fatalf("failed reading data from testdata/synthetic: %s", err)
+	}
+	expResp, err := NewShowVersionFromBytes(jsonContent)
+	if err != nil {
+		t.Fatalf("failed parsing json: %s", err)
+	}
+	resp := &ShowVersionResponse{}
+	if err := DecodeXML(xmlContent, resp); err != nil {
+		t.Fatalf("failed parsing xml: %s", err)
+	}
+	if !reflect.DeepEqual(resp, expResp) {
+		t.Fatalf("xml and json outputs differ:\nxml:  %#v\njson: %#v", resp, expResp)
+	}
+}
+
+func TestClientCliShowXML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		if req.Header.Get("Content-Type") != "application/xml" {
+			http.Error(w, "Bad Request, unexpected content type", http.StatusBadRequest)
+			return
+		}
+		var params InsAPIRequestParameters
+		if err := xml.Unmarshal(body, &params); err != nil {
+			http.Error(w, fmt.Sprintf("Bad Request, xml.Unmarshal: %s", err), http.StatusBadRequest)
+			return
+		}
+		if params.Format != OutputFormatXML {
+			http.Error(w, fmt.Sprintf("Bad Request, unexpected request: %s", body), http.StatusBadRequest)
+			return
+		}
+		if params.Input != "show version" {
+			fmt.Fprintf(w, `<?xml version="1.0"?><ins_api><type>cli_show</type><version>1.0</version><sid>eoc</sid>`+
+				`<outputs><output><input>%s</input><msg>Input CLI command error</msg><code>400</code>`+
+				`<clierror>%% Invalid command</clierror></output></outputs></ins_api>`, params.Input)
+			return
+		}
+		content, err := ioutil.ReadFile("testdata/synthetic/resp.show.version.xml")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	cli := newTestClient(server.URL)
+	var result ShowVersionResponseResult
+	if err := cli.CliShowXML(context.Background(), "show version", &result); err != nil {
+		t.Fatalf("client: %s", err)
+	}
+	if result.Code != "200" || result.Body.HostName != "macsec2" || result.Body.Memory != 16400780 {
+		t.Fatalf("client: unexpected result: %#v", result)
+	}
+	err := cli.CliShowXML(context.Background(), "show foo", &result)
+	if !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("client: expected invalid command error, got: %v", err)
+	}
+}